@@ -0,0 +1,57 @@
+package main
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/gotech-hub/dashgen/internal/bootstrap"
+	"github.com/gotech-hub/dashgen/internal/generator"
+	"github.com/gotech-hub/dashgen/internal/gomod"
+)
+
+// runInit writes the support files of a new project and generates its
+// sample entity.
+func runInit(cfg generator.Config, logw io.Writer) int {
+	opts := bootstrap.Options{
+		Framework:        *flagFramework,
+		Backend:          *flagBackend,
+		Style:            *flagStyle,
+		Module:           cfg.ModulePath,
+		ConstantsFile:    *flagConstantsFile,
+		ConstantsPackage: *flagConstantsPkg,
+	}
+	if mods, err := gomod.NewResolver(cfg.ProjectRoot); err == nil {
+		if opts.Module == "" {
+			if opts.Module, err = mods.ImportPath(cfg.ProjectRoot); err != nil {
+				fmt.Fprintln(logw, "❌", err)
+				return exitError
+			}
+		}
+	} else if opts.Module == "" {
+		fmt.Fprintln(logw, "❌ no go.mod found: pass --module to create one")
+		return exitUsage
+	} else {
+		opts.GoMod = true
+	}
+
+	files, err := bootstrap.Files(opts)
+	if err != nil {
+		fmt.Fprintln(logw, "❌", err)
+		return exitUsage
+	}
+	if _, err := generator.Write(files, cfg); err != nil {
+		fmt.Fprintln(logw, "❌", err)
+		return exitError
+	}
+	if cfg.DryRun {
+		return exitOK
+	}
+
+	// The project now has a go.mod and a sample entity to generate.
+	cfg.ModulePath = opts.Module
+	if code := run(cfg, logw); code != exitOK {
+		return code
+	}
+	fmt.Fprintln(logw, "👉 Run `go mod tidy` to fetch the dependencies of the generated code.")
+	return exitOK
+}
@@ -1,11 +1,15 @@
 package main
 
 import (
+	"encoding/json"
+	"errors"
 	"flag"
 	"fmt"
+	"io"
 	"log"
 	"os"
-	"path/filepath"
+	"runtime"
+	"strings"
 
 	"github.com/gotech-hub/dashgen/internal/generator"
 
@@ -19,17 +23,60 @@ var (
 	BuildTime = "unknown"
 )
 
+// Exit codes. The flag package already uses 2 for usage errors.
+const (
+	exitOK         = 0
+	exitError      = 1
+	exitUsage      = 2
+	exitDrift      = 3
+	exitNoEntities = 4
+)
+
 var (
-	flagModule  = flag.String("module", "github.com/your-org/app", "go module path of the target project (for imports)")
-	flagRoot    = flag.String("root", ".", "target project root (where model/ lives)")
-	flagModel   = flag.String("model", "", "single data.go path to parse (optional)")
-	flagForce   = flag.Bool("force", false, "overwrite existing files if present")
-	flagDryRun  = flag.Bool("dry", false, "print actions without writing files")
-	flagVersion = flag.Bool("version", false, "print version information")
+	flagModule    = flag.String("module", "", "go module path of the target project (for imports); detected from go.mod/go.work when empty")
+	flagRoot      = flag.String("root", ".", "target project root (where model/ lives)")
+	flagModel     = flag.String("model", "", "single data.go path to parse (optional)")
+	flagForce     = flag.Bool("force", false, "overwrite existing files if present")
+	flagDryRun    = flag.Bool("dry", false, "print actions without writing files")
+	flagVersion   = flag.Bool("version", false, "print version information")
+	flagJobs      = flag.Int("j", runtime.NumCPU(), "number of entities to render in parallel")
+	flagReport    = flag.String("report", "text", "output format of the run report: text or json")
+	flagCheck     = flag.Bool("check", false, "dry run that exits with code 3 when generated files are out of date")
+	flagKeep      = flag.Bool("keep-going", false, "generate every valid entity and report all errors at the end")
+	flagBackend   = flag.String("backend", generator.DefaultBackend, "storage backend of the model layer ("+strings.Join(generator.Backends(), ", ")+")")
+	flagFramework = flag.String("framework", generator.DefaultFramework, "HTTP framework of the api and routes layers ("+strings.Join(generator.Frameworks(), ", ")+")")
+	flagStyle     = flag.String("style", generator.DefaultStyle, "how generated layers reach their dependencies ("+strings.Join(generator.Styles(), ", ")+")")
+	flagTpls      = flag.String("templates", "", "directory of *.tmpl files overriding the built-in templates")
+	flagOnly      = flag.String("only", "", "comma-separated layers to generate (model,repository,action,api,client,constants or a custom layer)")
+	flagSkip      = flag.String("skip", "", "comma-separated layers not to generate")
+	flagEntity    = flag.String("entity", "", "comma-separated entity names or globs to generate (e.g. User,Order*)")
+	flagExclude   = flag.String("exclude-entity", "", "comma-separated entity names or globs not to generate")
+
+	flagConstantsFile = flag.String("constants-file", generator.DefaultConstantsFile, "file holding the API parameter constants, relative to --root")
+	flagConstantsPkg  = flag.String("constants-pkg", generator.DefaultConstantsPackage, "package name used when the constants file is created")
+	flagConstantName  = flag.String("constants-name", generator.DefaultConstantName, "constant naming scheme; {Entity} is replaced by the entity name")
+	flagPrune         = flag.Bool("prune-constants", false, "remove constants following the naming scheme whose entity no longer exists (full runs only)")
 )
 
 func main() {
-	flag.Parse()
+	// Subcommands come before the flags: dashgen watch --root=...
+	args := os.Args[1:]
+	var cmd, name string
+	switch {
+	case len(args) > 0 && (args[0] == "watch" || args[0] == "init"):
+		cmd, args = args[0], args[1:]
+	case len(args) > 0 && args[0] == "new":
+		if len(args) < 3 || args[1] != "entity" {
+			fmt.Fprintln(os.Stderr, "usage: dashgen new entity <Name> [--field name:type[:options]]... [--db name] [-i]")
+			os.Exit(exitUsage)
+		}
+		cmd, name, args = args[0], args[2], args[3:]
+	}
+	flag.CommandLine.Parse(args)
+	if err := applyConfigFile(*flagRoot); err != nil {
+		fmt.Fprintln(os.Stderr, "❌", err)
+		os.Exit(exitUsage)
+	}
 
 	// Handle version flag
 	if *flagVersion {
@@ -39,45 +86,182 @@ func main() {
 		return
 	}
 
-	var entities []parser.Entity
+	if *flagReport != "text" && *flagReport != "json" {
+		fmt.Fprintf(os.Stderr, "invalid --report %q: want text or json\n", *flagReport)
+		os.Exit(exitUsage)
+	}
 
-	if *flagModel != "" {
-		e, err := parser.ParseDataGo(*flagModel)
-		if err != nil {
-			log.Fatalf("parse %s: %v", *flagModel, err)
-		}
-		entities = append(entities, e...)
-	} else {
-		matches, err := filepath.Glob(filepath.Join(*flagRoot, "model/**/data.go"))
-		if err != nil {
+	// In JSON mode stdout only carries the report.
+	logw := io.Writer(os.Stdout)
+	if *flagReport == "json" {
+		logw = os.Stderr
+	}
+
+	cfg := generator.Config{
+		ModulePath:  *flagModule,
+		ProjectRoot: *flagRoot,
+		Force:       *flagForce,
+		DryRun:      *flagDryRun || *flagCheck,
+		Jobs:        *flagJobs,
+		Log:         logw,
+		KeepGoing:   *flagKeep,
+
+		Only:         splitList(*flagOnly),
+		Skip:         splitList(*flagSkip),
+		Version:      Version,
+		Framework:    *flagFramework,
+		Backend:      *flagBackend,
+		Style:        *flagStyle,
+		TemplatesDir: *flagTpls,
+
+		ConstantsFile:    *flagConstantsFile,
+		ConstantsPackage: *flagConstantsPkg,
+		ConstantName:     *flagConstantName,
+		// Only a run over the whole model tree knows which entities are gone.
+		PruneConstants: *flagPrune && *flagModel == "" && *flagEntity == "" && *flagExclude == "",
+		ProjectFiles:   *flagModel == "" && *flagEntity == "" && *flagExclude == "",
+	}
+
+	if cmd == "watch" {
+		if err := runWatch(cfg); err != nil {
 			log.Fatal(err)
 		}
-		if len(matches) == 0 {
-			log.Fatalf("no data.go found under %s", filepath.Join(*flagRoot, "model/**/data.go"))
-		}
-		for _, p := range matches {
-			e, perr := parser.ParseDataGo(p)
-			if perr != nil {
-				log.Fatalf("parse %s: %v", p, perr)
+		return
+	}
+
+	if cmd == "init" {
+		os.Exit(runInit(cfg, logw))
+	}
+
+	if cmd == "new" {
+		os.Exit(runNew(cfg, logw, name))
+	}
+
+	os.Exit(run(cfg, logw))
+}
+
+// run generates the project and returns the process exit code.
+func run(cfg generator.Config, logw io.Writer) int {
+	report := generator.NewReport(cfg.DryRun)
+
+	paths, err := discover()
+	if err != nil {
+		report.Diagnostics = append(report.Diagnostics, generator.Diagnostic{Severity: "error", Message: err.Error()})
+		fmt.Fprintln(logw, "❌", err)
+		return finish(report, exitNoEntities)
+	}
+
+	entities, perr := parser.ParseFiles(paths)
+	var failures []error
+	if perr != nil {
+		for _, err := range unwrapJoined(perr) {
+			var fe *parser.FileError
+			if errors.As(err, &fe) {
+				report.Diagnostics = append(report.Diagnostics, generator.Diagnostic{Severity: "error", File: fe.Path, Message: fe.Err.Error()})
 			}
-			entities = append(entities, e...)
+			fmt.Fprintf(logw, "❌ %v\n", err)
+			failures = append(failures, err)
+		}
+		if !cfg.KeepGoing {
+			return finish(report, exitError)
 		}
+		// The entities of broken files are unknown, so their constants
+		// must not be pruned, and neither the route table nor the registry
+		// may be rewritten without them.
+		cfg.PruneConstants = false
+		cfg.Skip = append(cfg.Skip, generator.LayerRoutes, generator.LayerRegistry)
+		fmt.Fprintf(logw, "⚠️  %s and %s not updated: some model files failed to parse\n", generator.RoutesFile, generator.RegistryFile)
+	}
+
+	entities, err = parser.Filter(entities, splitList(*flagEntity), splitList(*flagExclude))
+	if err != nil {
+		report.Diagnostics = append(report.Diagnostics, generator.Diagnostic{Severity: "error", Message: err.Error()})
+		fmt.Fprintln(logw, "❌", err)
+		return finish(report, exitError)
 	}
 
-	fmt.Printf("Total entities to generate: %d\n", len(entities))
+	fmt.Fprintf(logw, "Total entities to generate: %d\n", len(entities))
 	for i, e := range entities {
-		fmt.Printf("Entity %d: %s (pkg: %s, db: %s)\n", i+1, e.Name, e.PkgPath, e.DBName)
+		fmt.Fprintf(logw, "Entity %d: %s (pkg: %s, db: %s)\n", i+1, e.Name, e.PkgPath, e.DBName)
+	}
+	if len(entities) == 0 {
+		if len(failures) > 0 {
+			printFailures(logw, failures)
+			return finish(report, exitError)
+		}
+		report.Diagnostics = append(report.Diagnostics, generator.Diagnostic{Severity: "error", Message: "no @entity found"})
+		fmt.Fprintln(logw, "❌ no @entity found")
+		return finish(report, exitNoEntities)
 	}
 
-	cfg := generator.Config{
-		ModulePath:  *flagModule,
-		ProjectRoot: *flagRoot,
-		Force:       *flagForce,
-		DryRun:      *flagDryRun,
+	genReport, err := generator.Generate(entities, cfg)
+	genReport.Diagnostics = append(report.Diagnostics, genReport.Diagnostics...)
+	fmt.Fprintf(logw, "Summary: %d created, %d overwritten, %d skipped (existing), %d stale, %d unchanged, %d failed\n",
+		genReport.Count(generator.ActionCreated), genReport.Count(generator.ActionOverwritten),
+		genReport.Count(generator.ActionSkippedExisting), genReport.Count(generator.ActionStale), genReport.Count(generator.ActionUnchanged),
+		genReport.Count(generator.ActionError))
+	if err != nil {
+		failures = append(failures, unwrapJoined(err)...)
+	}
+	if len(failures) > 0 {
+		printFailures(logw, failures)
+		return finish(genReport, exitError)
+	}
+	if *flagCheck && genReport.Drift() {
+		fmt.Fprintln(logw, "❌ Generated files are out of date.")
+		return finish(genReport, exitDrift)
+	}
+	fmt.Fprintln(logw, "✅ Generation finished.")
+	return finish(genReport, exitOK)
+}
+
+// printFailures prints the aggregated error summary of a run.
+func printFailures(logw io.Writer, failures []error) {
+	fmt.Fprintf(logw, "❌ %d error(s):\n", len(failures))
+	for _, err := range failures {
+		fmt.Fprintf(logw, "  - %v\n", err)
 	}
-	if err := generator.Generate(entities, cfg); err != nil {
-		fmt.Fprintln(os.Stderr, "generate error:", err)
-		os.Exit(1)
+}
+
+// unwrapJoined splits an error created by errors.Join into its parts.
+func unwrapJoined(err error) []error {
+	if j, ok := err.(interface{ Unwrap() []error }); ok {
+		return j.Unwrap()
+	}
+	return []error{err}
+}
+
+// finish prints the JSON report when requested and passes code through.
+func finish(report *generator.Report, code int) int {
+	if *flagReport != "json" {
+		return code
+	}
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(report); err != nil {
+		fmt.Fprintln(os.Stderr, "write report:", err)
+		return exitError
+	}
+	return code
+}
+
+// discover returns the data.go files selected by --model or found under
+// --root.
+func discover() ([]string, error) {
+	if *flagModel != "" {
+		return []string{*flagModel}, nil
+	}
+
+	return parser.Discover(*flagRoot)
+}
+
+// splitList splits a comma-separated flag value, dropping empty items.
+func splitList(s string) []string {
+	var out []string
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			out = append(out, item)
+		}
 	}
-	fmt.Println("✅ Generation finished.")
+	return out
 }
@@ -0,0 +1,124 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/gotech-hub/dashgen/internal/generator"
+	"github.com/gotech-hub/dashgen/internal/scaffold"
+)
+
+// fieldsFlag collects repeated --field flags.
+type fieldsFlag []string
+
+func (f *fieldsFlag) String() string { return strings.Join(*f, " ") }
+
+func (f *fieldsFlag) Set(v string) error {
+	*f = append(*f, v)
+	return nil
+}
+
+var (
+	flagFields      fieldsFlag
+	flagDB          = flag.String("db", "", "new: collection name of the new entity")
+	flagPkg         = flag.String("pkg", "", "new: model package of the new entity (default: lower-case entity name)")
+	flagInteractive = flag.Bool("i", false, "new: prompt for the collection name and fields")
+)
+
+func init() {
+	flag.Var(&flagFields, "field", "new: field as name:type[:options], e.g. total:float64:required,min=0 (repeatable)")
+}
+
+// runNew writes model/<pkg>/data.go for a new entity and generates it.
+func runNew(cfg generator.Config, logw io.Writer, name string) int {
+	e := scaffold.Entity{Name: name, Package: *flagPkg, DBName: *flagDB}
+	if e.Package == "" {
+		e.Package = scaffold.PackageName(name)
+	}
+
+	specs := []string(flagFields)
+	if *flagInteractive {
+		var err error
+		if specs, err = prompt(os.Stdin, logw, &e, specs); err != nil {
+			fmt.Fprintln(logw, "❌", err)
+			return exitError
+		}
+	}
+	for _, spec := range specs {
+		f, err := scaffold.ParseField(spec)
+		if err != nil {
+			fmt.Fprintln(logw, "❌", err)
+			return exitUsage
+		}
+		e.Fields = append(e.Fields, f)
+	}
+
+	path := filepath.Join(cfg.ProjectRoot, "model", e.Package, "data.go")
+	existing, err := os.ReadFile(path)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		fmt.Fprintln(logw, "❌", err)
+		return exitError
+	}
+	src, err := scaffold.DataGo(e, existing)
+	if err != nil {
+		fmt.Fprintf(logw, "❌ %s: %v\n", path, err)
+		return exitError
+	}
+
+	if cfg.DryRun {
+		fmt.Fprintln(logw, "would write:", path)
+		logw.Write(src)
+		return exitOK
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		fmt.Fprintln(logw, "❌", err)
+		return exitError
+	}
+	if err := os.WriteFile(path, src, 0o644); err != nil {
+		fmt.Fprintln(logw, "❌", err)
+		return exitError
+	}
+	fmt.Fprintf(logw, "✅ Added entity %s to: %s\n", name, path)
+
+	// Generate the whole project, so that project-level files such as the
+	// route registration pick up the new entity. The files of the other
+	// entities are unchanged or skipped as existing.
+	return run(cfg, logw)
+}
+
+// prompt asks for the collection name when it is not set and for fields
+// until an empty line, after the ones given on the command line.
+func prompt(in io.Reader, out io.Writer, e *scaffold.Entity, specs []string) ([]string, error) {
+	sc := bufio.NewScanner(in)
+	ask := func(q string) (string, bool) {
+		fmt.Fprint(out, q)
+		if !sc.Scan() {
+			return "", false
+		}
+		return strings.TrimSpace(sc.Text()), true
+	}
+
+	if e.DBName == "" {
+		if db, ok := ask("Collection name (empty for the default): "); ok {
+			e.DBName = db
+		}
+	}
+	for {
+		spec, ok := ask("Field as name:type[:options] (empty to finish): ")
+		if !ok || spec == "" {
+			break
+		}
+		if _, err := scaffold.ParseField(spec); err != nil {
+			fmt.Fprintln(out, "❌", err)
+			continue
+		}
+		specs = append(specs, spec)
+	}
+	return specs, sc.Err()
+}
@@ -0,0 +1,187 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"sort"
+	"time"
+
+	"github.com/gotech-hub/dashgen/internal/generator"
+	"github.com/gotech-hub/dashgen/internal/parser"
+)
+
+var (
+	flagInterval = flag.Duration("interval", 500*time.Millisecond, "watch: how often model files are polled")
+	flagDebounce = flag.Duration("debounce", 300*time.Millisecond, "watch: quiet period before regenerating")
+)
+
+// fileState is what the watcher remembers about a data.go file.
+type fileState struct {
+	modTime time.Time
+	size    int64
+}
+
+// runWatch polls the discovered data.go files and regenerates the entities
+// of every file that changed. Parse and generate errors are reported inline
+// and never stop the watcher.
+//
+// The first scan generates everything with the given --force. After that
+// the outputs of changed files are overwritten when they still carry the
+// dashgen header; files whose header was removed belong to the user and are
+// left alone, as are files whose content did not change, so reloaders like
+// air only restart on real changes. Project-level files are rendered from
+// all entities after every batch.
+func runWatch(cfg generator.Config) error {
+	// Only changed entities are regenerated, so nothing may be pruned.
+	cfg.PruneConstants = false
+	projectFiles := cfg.ProjectFiles
+	cfg.ProjectFiles = false
+	// One broken entity must not block the others.
+	cfg.KeepGoing = true
+	changed := cfg
+	changed.Refresh = true
+
+	stop := make(chan os.Signal, 1)
+	signal.Notify(stop, os.Interrupt)
+
+	seen := map[string]fileState{}
+	pending := map[string]bool{}
+	var lastChange time.Time
+
+	// The first scan generates everything once.
+	scan(seen, pending)
+	regenerate(pending, cfg, projectFiles)
+	clear(pending)
+	fmt.Printf("👀 Watching %d model file(s) under %s (Ctrl+C to stop)\n", len(seen), *flagRoot)
+
+	ticker := time.NewTicker(*flagInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-stop:
+			fmt.Println("👋 Stopped watching.")
+			return nil
+		case <-ticker.C:
+		}
+
+		if scan(seen, pending) {
+			lastChange = time.Now()
+		}
+		if len(pending) == 0 || time.Since(lastChange) < *flagDebounce {
+			continue
+		}
+
+		regenerate(pending, changed, projectFiles)
+		clear(pending)
+	}
+}
+
+// scan refreshes seen and marks new or modified files as pending. It reports
+// whether anything changed since the previous scan.
+func scan(seen map[string]fileState, pending map[string]bool) bool {
+	paths, err := discover()
+	if err != nil {
+		// Keep the previous snapshot: a failed walk must not look like
+		// every file was removed.
+		fmt.Fprintf(os.Stderr, "❌ discover: %v\n", err)
+		return false
+	}
+
+	changed := false
+	current := map[string]bool{}
+	for _, p := range paths {
+		current[p] = true
+		info, err := os.Stat(p)
+		if err != nil {
+			continue
+		}
+		st := fileState{modTime: info.ModTime(), size: info.Size()}
+		if prev, ok := seen[p]; ok && prev == st {
+			continue
+		}
+		seen[p] = st
+		pending[p] = true
+		changed = true
+	}
+
+	for p := range seen {
+		if !current[p] {
+			delete(seen, p)
+			delete(pending, p)
+			fmt.Printf("🗑️  %s removed; generated files were left in place\n", p)
+			changed = true
+		}
+	}
+	return changed
+}
+
+// regenerate re-parses the pending files and regenerates their entities,
+// then the project-level files when projectFiles is set.
+func regenerate(pending map[string]bool, cfg generator.Config, projectFiles bool) {
+	paths := make([]string, 0, len(pending))
+	for p := range pending {
+		paths = append(paths, p)
+	}
+	sort.Strings(paths)
+
+	var entities []parser.Entity
+	for _, p := range paths {
+		e, err := parser.ParseDataGo(p)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "❌ parse %s: %v\n", p, err)
+			continue
+		}
+		if len(e) == 0 {
+			fmt.Fprintf(os.Stderr, "⚠️  %s: no @entity found\n", p)
+			continue
+		}
+		// A changed file need not declare a filtered entity, so patterns
+		// that match nothing here are fine.
+		e, _ = parser.Filter(e, splitList(*flagEntity), splitList(*flagExclude))
+		for _, en := range e {
+			fmt.Printf("🔄 %s changed, regenerating %s\n", p, en.Name)
+		}
+		entities = append(entities, e...)
+	}
+	if len(entities) == 0 {
+		return
+	}
+
+	if _, err := generator.Generate(entities, cfg); err != nil {
+		for _, e := range unwrapJoined(err) {
+			fmt.Fprintln(os.Stderr, "❌", e)
+		}
+		return
+	}
+	fmt.Printf("✅ Regenerated %d entities at %s\n", len(entities), time.Now().Format("15:04:05"))
+	if projectFiles {
+		regenerateProjectFiles(cfg)
+	}
+}
+
+// regenerateProjectFiles renders the project-level files from every entity,
+// so that entities added while watching reach the route table and the
+// registry. Nothing is written while a data.go fails to parse, as that
+// would drop its entities.
+func regenerateProjectFiles(cfg generator.Config) {
+	paths, err := discover()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "❌", err)
+		return
+	}
+	entities, err := parser.ParseFiles(paths)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "⚠️  %s and %s not updated: some model files failed to parse\n", generator.RoutesFile, generator.RegistryFile)
+		return
+	}
+	files, err := generator.RenderProjectFiles(entities, cfg)
+	if err == nil {
+		_, err = generator.Write(files, cfg)
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "❌", err)
+	}
+}
@@ -2,6 +2,4 @@ module github.com/gotech-hub/dashgen
 
 go 1.24
 
-require github.com/gotech-hub/dashgen v0.0.0
-
 replace github.com/gotech-hub/dashgen => ./
@@ -0,0 +1,157 @@
+// Package bootstrap renders the support files a project needs before the
+// generated code compiles: the helpers and base client the templates refer
+// to, the constants package, a dashgen config, a sample entity and a
+// main.go wiring example.
+package bootstrap
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"path"
+	"slices"
+	"strings"
+	"text/template"
+
+	"github.com/gotech-hub/dashgen/internal/generator"
+	"github.com/gotech-hub/dashgen/internal/scaffold"
+	"github.com/gotech-hub/dashgen/internal/templates"
+)
+
+// ConfigFile is the dashgen config written to the project root. Its keys
+// are command line flag names.
+const ConfigFile = "dashgen.json"
+
+// Options describes the project to bootstrap.
+type Options struct {
+	Framework string // one of generator.Frameworks()
+	Backend   string // one of generator.Backends()
+	Style     string // one of generator.Styles()
+	Module    string // module path of the project root
+	GoMod     bool   // also write go.mod for Module
+
+	ConstantsFile    string // relative to the project root
+	ConstantsPackage string
+}
+
+// supportFile is a file written for every framework.
+type supportFile struct {
+	path  string
+	layer string
+	tpl   string
+}
+
+var supportFiles = []supportFile{
+	{path: "internal/utils/utils.go", layer: "utils", tpl: templates.SDKUtils},
+	{path: "client/client.go", layer: "client", tpl: templates.SDKClient},
+}
+
+// Files of every framework but sdk, which imports the same types from
+// go-common and go-mongodb.
+var (
+	commonFile     = supportFile{path: path.Join(generator.CommonDir, "common.go"), layer: "common", tpl: templates.HTTPCommon}
+	collectionFile = supportFile{path: path.Join(generator.CollectionDir, "collection.go"), layer: "collection", tpl: templates.HTTPMongoCollection}
+)
+
+// mainExamples maps a framework to its main.go wiring example.
+var mainExamples = map[string]string{
+	generator.FrameworkSDK:     templates.SDKMainExample,
+	generator.FrameworkNetHTTP: templates.HTTPMainExample,
+	generator.FrameworkChi:     templates.HTTPMainExample,
+	generator.FrameworkGin:     templates.HTTPMainExample,
+	generator.FrameworkEcho:    templates.HTTPMainExample,
+}
+
+// sample is the entity written to model/<package>/data.go.
+var sample = scaffold.Entity{
+	Name: "User",
+	Fields: []scaffold.Field{
+		{Name: "Name", Type: "string", Validate: []string{"required", "min=2", "max=100"}},
+		{Name: "Email", Type: "string", Validate: []string{"required", "email"}, Index: "unique"},
+	},
+}
+
+// Files renders the files of a new project, relative to its root.
+func Files(opts Options) ([]generator.File, error) {
+	framework := opts.Framework
+	if framework == "" {
+		framework = generator.DefaultFramework
+	}
+	example, ok := mainExamples[framework]
+	if !ok {
+		return nil, fmt.Errorf("unknown framework %q (available: %s)", framework, strings.Join(generator.Frameworks(), ", "))
+	}
+	backend := opts.Backend
+	if backend == "" {
+		backend = generator.DefaultBackend
+	}
+	if !slices.Contains(generator.Backends(), backend) {
+		return nil, fmt.Errorf("unknown backend %q (available: %s)", backend, strings.Join(generator.Backends(), ", "))
+	}
+	style := opts.Style
+	if style == "" {
+		style = generator.DefaultStyle
+	}
+	if !slices.Contains(generator.Styles(), style) {
+		return nil, fmt.Errorf("unknown style %q (available: %s)", style, strings.Join(generator.Styles(), ", "))
+	}
+	support := append(slices.Clone(supportFiles), supportFile{path: "main.go.example", layer: "main", tpl: example})
+	if framework != generator.FrameworkSDK {
+		support = append(support, commonFile)
+		if backend == generator.BackendMongo {
+			support = append(support, collectionFile)
+		}
+	}
+
+	e := sample
+	e.Package = scaffold.PackageName(e.Name)
+	ctx := map[string]string{
+		"Module":    opts.Module,
+		"Name":      path.Base(opts.Module),
+		"Framework": framework,
+		"Backend":   backend,
+		"Style":     style,
+	}
+
+	var files []generator.File
+	if opts.GoMod {
+		files = append(files, generator.File{Path: "go.mod", Layer: "gomod", Content: []byte("module " + opts.Module + "\n\ngo 1.24\n")})
+	}
+
+	config, err := json.MarshalIndent(map[string]string{
+		"framework":      framework,
+		"backend":        backend,
+		"style":          style,
+		"constants-file": opts.ConstantsFile,
+		"constants-pkg":  opts.ConstantsPackage,
+	}, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	files = append(files, generator.File{Path: ConfigFile, Layer: "config", Content: append(config, '\n')})
+
+	for _, f := range support {
+		var buf bytes.Buffer
+		t, err := template.New(f.path).Parse(f.tpl)
+		if err == nil {
+			err = t.Execute(&buf, ctx)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("render %s: %w", f.path, err)
+		}
+		files = append(files, generator.File{Path: f.path, Layer: f.layer, Content: buf.Bytes()})
+	}
+
+	files = append(files, generator.File{
+		Path:    opts.ConstantsFile,
+		Layer:   generator.LayerConstants,
+		Content: []byte("package " + opts.ConstantsPackage + "\n"),
+	})
+
+	src, err := scaffold.DataGo(e, nil)
+	if err != nil {
+		return nil, err
+	}
+	files = append(files, generator.File{Path: path.Join("model", e.Package, "data.go"), Layer: "entity", Content: src})
+	return files, nil
+}
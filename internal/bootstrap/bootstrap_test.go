@@ -0,0 +1,155 @@
+package bootstrap
+
+import (
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/gotech-hub/dashgen/internal/generator"
+	"github.com/gotech-hub/dashgen/internal/parser"
+)
+
+// TestInitBuilds runs what "dashgen init" does into an empty directory and
+// builds the result.
+func TestInitBuilds(t *testing.T) {
+	if testing.Short() {
+		t.Skip("builds generated projects")
+	}
+	if _, err := exec.LookPath("go"); err != nil {
+		t.Skip("go tool not found")
+	}
+	tests := []struct {
+		framework, backend, style string
+	}{
+		{generator.FrameworkNetHTTP, generator.BackendSQLite, generator.StyleGlobal},
+		{generator.FrameworkNetHTTP, generator.BackendSQLite, generator.StyleDI},
+		{generator.FrameworkNetHTTP, generator.BackendPostgres, generator.StyleGlobal},
+		// These need modules from the network.
+		{generator.FrameworkNetHTTP, generator.BackendMongo, generator.StyleGlobal},
+		{generator.FrameworkChi, generator.BackendSQLite, generator.StyleDI},
+	}
+	for _, tt := range tests {
+		t.Run(tt.framework+"/"+tt.backend+"/"+tt.style, func(t *testing.T) {
+			dir := t.TempDir()
+			cfg := initProject(t, dir, tt.framework, tt.backend, tt.style)
+			generate(t, cfg)
+
+			if out, err := goCmd(dir, "mod", "tidy"); err != nil {
+				if tt.backend == generator.BackendMongo || tt.framework != generator.FrameworkNetHTTP {
+					t.Skipf("dependencies unavailable: %s", lastLine(out))
+				}
+				t.Fatalf("go mod tidy: %v\n%s", err, out)
+			}
+			if out, err := goCmd(dir, "build", "./..."); err != nil {
+				t.Fatalf("go build: %v\n%s", err, out)
+			}
+		})
+	}
+}
+
+// TestPartialRunBuilds builds a project whose entities were generated by a
+// partial run only, which does not render the route registration file.
+func TestPartialRunBuilds(t *testing.T) {
+	if testing.Short() {
+		t.Skip("builds generated projects")
+	}
+	if _, err := exec.LookPath("go"); err != nil {
+		t.Skip("go tool not found")
+	}
+	// Each test changes the configuration of a full run into the partial
+	// runs it makes in turn.
+	tests := map[string][]func(*generator.Config){
+		"entity filter": {func(cfg *generator.Config) { cfg.ProjectFiles = false }},
+		"skip routes":   {func(cfg *generator.Config) { cfg.Skip = []string{generator.LayerRoutes} }},
+		"only api": {
+			func(cfg *generator.Config) { cfg.Skip = []string{generator.LayerAPI, generator.LayerRoutes} },
+			func(cfg *generator.Config) { cfg.Only = []string{generator.LayerAPI} },
+		},
+	}
+	for name, runs := range tests {
+		t.Run(name, func(t *testing.T) {
+			dir := t.TempDir()
+			full := initProject(t, dir, generator.FrameworkNetHTTP, generator.BackendSQLite, generator.StyleGlobal)
+			for _, partial := range runs {
+				cfg := full
+				partial(&cfg)
+				generate(t, cfg)
+			}
+			if _, err := os.Stat(filepath.Join(dir, generator.RoutesFile)); !os.IsNotExist(err) {
+				t.Fatalf("%s rendered by a partial run", generator.RoutesFile)
+			}
+
+			if out, err := goCmd(dir, "mod", "tidy"); err != nil {
+				t.Fatalf("go mod tidy: %v\n%s", err, out)
+			}
+			if out, err := goCmd(dir, "build", "./..."); err != nil {
+				t.Fatalf("go build: %v\n%s", err, out)
+			}
+		})
+	}
+}
+
+// initProject writes what "dashgen init" does into dir, without generating
+// the sample entity, and returns the configuration of a full run on it.
+func initProject(t *testing.T, dir, framework, backend, style string) generator.Config {
+	t.Helper()
+	cfg := generator.Config{
+		ModulePath:       "example.com/app",
+		ProjectRoot:      dir,
+		Log:              io.Discard,
+		Framework:        framework,
+		Backend:          backend,
+		Style:            style,
+		ConstantsFile:    generator.DefaultConstantsFile,
+		ConstantsPackage: generator.DefaultConstantsPackage,
+		ProjectFiles:     true,
+	}
+	files, err := Files(Options{
+		Framework:        framework,
+		Backend:          backend,
+		Style:            style,
+		Module:           cfg.ModulePath,
+		GoMod:            true,
+		ConstantsFile:    cfg.ConstantsFile,
+		ConstantsPackage: cfg.ConstantsPackage,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := generator.Write(files, cfg); err != nil {
+		t.Fatal(err)
+	}
+	return cfg
+}
+
+// generate generates the entities discovered below cfg.ProjectRoot.
+func generate(t *testing.T, cfg generator.Config) {
+	t.Helper()
+	paths, err := parser.Discover(cfg.ProjectRoot)
+	if err != nil {
+		t.Fatal(err)
+	}
+	entities, err := parser.ParseFiles(paths)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := generator.Generate(entities, cfg); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func goCmd(dir string, args ...string) (string, error) {
+	cmd := exec.Command("go", args...)
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), "GOFLAGS=-mod=mod", "GOWORK=off")
+	out, err := cmd.CombinedOutput()
+	return string(out), err
+}
+
+func lastLine(s string) string {
+	s = strings.TrimSpace(s)
+	return strings.TrimSpace(s[strings.LastIndex(s, "\n")+1:])
+}
@@ -0,0 +1,79 @@
+package generator
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/gotech-hub/dashgen/internal/templates"
+)
+
+// Storage backends the model layer can target.
+const (
+	BackendMongo    = "mongo"
+	BackendPostgres = "postgres"
+	BackendSQLite   = "sqlite"
+)
+
+// DefaultBackend is used when Config.Backend is empty.
+const DefaultBackend = BackendMongo
+
+// backend describes the model layer of a storage backend.
+type backend struct {
+	init       string // source of the init template
+	repository string // source of the repository template
+	dialect    string // SQL dialect; empty for MongoDB
+	dbType     string // database handle passed to Init and InitAll
+	dbImport   string // import path of dbType
+}
+
+var backends = map[string]backend{
+	BackendMongo: {
+		init:       templates.ModelInit,
+		repository: templates.ModelRepository,
+		dbType:     "*mongo.Database",
+		dbImport:   "go.mongodb.org/mongo-driver/mongo",
+	},
+	BackendPostgres: {
+		init:       templates.SQLInit,
+		repository: templates.SQLRepository,
+		dialect:    dialectPostgres,
+		dbType:     "*sql.DB",
+		dbImport:   "database/sql",
+	},
+	BackendSQLite: {
+		init:       templates.SQLInit,
+		repository: templates.SQLRepository,
+		dialect:    dialectSQLite,
+		dbType:     "*sql.DB",
+		dbImport:   "database/sql",
+	},
+}
+
+// Backends returns the supported storage backends, sorted.
+func Backends() []string {
+	var names []string
+	for name := range backends {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// backend returns the name and description of the configured backend.
+func (cfg Config) backend() (string, backend, error) {
+	name := cfg.Backend
+	if name == "" {
+		name = DefaultBackend
+	}
+	b, ok := backends[name]
+	if !ok {
+		return "", backend{}, fmt.Errorf("unknown backend %q (available: %s)", name, strings.Join(Backends(), ", "))
+	}
+	return name, b, nil
+}
+
+// backendTemplate names the built-in template tpl of a backend.
+func backendTemplate(tpl, name string) string {
+	return tpl + "/" + name
+}
@@ -0,0 +1,335 @@
+package generator
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/format"
+	goparser "go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/gotech-hub/dashgen/internal/naming"
+	"github.com/gotech-hub/dashgen/internal/parser"
+)
+
+// Defaults for the API parameter constants file.
+const (
+	DefaultConstantsFile    = "utils/constants.go"
+	DefaultConstantsPackage = "constants"
+	DefaultConstantName     = "Param{Entity}ID"
+)
+
+// constantsDoc is the doc comment of the const block dashgen manages.
+const constantsDoc = "API parameter constants"
+
+// constantMarker is the line comment of the constants dashgen adds. It
+// plays the part of the generated-file header in the shared constants
+// file: only constants carrying it are ever pruned.
+const constantMarker = "generated by dashgen"
+
+func (cfg Config) constantsFile() string {
+	if cfg.ConstantsFile != "" {
+		return cfg.ConstantsFile
+	}
+	return DefaultConstantsFile
+}
+
+func (cfg Config) constantsPackage() string {
+	if cfg.ConstantsPackage != "" {
+		return cfg.ConstantsPackage
+	}
+	return DefaultConstantsPackage
+}
+
+// schemePlaceholders are the entity placeholders of a constant naming
+// scheme: "{Entity}" is the entity name as declared, "{ENTITY}" its
+// SCREAMING_SNAKE_CASE form and "{entity}" its snake_case form.
+var schemePlaceholders = []struct {
+	token   string
+	convert func(string) string
+}{
+	{"{Entity}", func(name string) string { return name }},
+	{"{ENTITY}", naming.Screaming},
+	{"{entity}", naming.Snake},
+}
+
+// constantScheme splits the constant naming scheme around its entity
+// placeholder and returns how the entity name is spelled in between.
+func (cfg Config) constantScheme() (prefix, suffix string, convert func(string) string) {
+	scheme := cfg.ConstantName
+	if scheme == "" {
+		scheme = DefaultConstantName
+	}
+	for _, p := range schemePlaceholders {
+		if before, after, ok := strings.Cut(scheme, p.token); ok {
+			return before, after, p.convert
+		}
+	}
+	return scheme, "", func(name string) string { return name }
+}
+
+// paramConstant returns the name and value of the API parameter constant
+// for an entity.
+func paramConstant(e parser.Entity, cfg Config) (name, value string) {
+	prefix, suffix, convert := cfg.constantScheme()
+	return prefix + convert(e.Name) + suffix, naming.Snake(e.Name) + "_id"
+}
+
+// ownsConstant reports whether vs was added by dashgen.
+func ownsConstant(vs *ast.ValueSpec) bool {
+	return vs.Comment != nil && strings.TrimSpace(vs.Comment.Text()) == constantMarker
+}
+
+// isManagedConstant reports whether name follows the constant naming scheme.
+func isManagedConstant(name string, cfg Config) bool {
+	prefix, suffix, _ := cfg.constantScheme()
+	return len(name) > len(prefix)+len(suffix) &&
+		strings.HasPrefix(name, prefix) && strings.HasSuffix(name, suffix)
+}
+
+// constEntry is one spec of the managed const block, kept as source text so
+// that comments survive the rewrite.
+type constEntry struct {
+	name string
+	src  string
+}
+
+// renderConstants adds the constants for all entities to the constants file
+// and returns the merged result, or nil when nothing changed. The file is edited
+// through its syntax tree: only the managed const block is rewritten, its
+// constants are kept sorted and the result is gofmt'ed. With
+// cfg.PruneConstants, constants dashgen added whose entity is gone are
+// removed; hand-written constants are never pruned.
+func renderConstants(entities []parser.Entity, cfg Config) (*File, constantsChange, error) {
+	constantsPath := filepath.Join(cfg.ProjectRoot, cfg.constantsFile())
+
+	src, err := os.ReadFile(constantsPath)
+	exists := err == nil
+	if err != nil && !os.IsNotExist(err) {
+		return nil, constantsChange{}, fmt.Errorf("failed to read constants file: %v", err)
+	}
+	if !exists {
+		src = []byte("package " + cfg.constantsPackage() + "\n")
+	}
+
+	fset := token.NewFileSet()
+	f, err := goparser.ParseFile(fset, constantsPath, src, goparser.ParseComments)
+	if err != nil {
+		return nil, constantsChange{}, fmt.Errorf("parse constants file: %w", err)
+	}
+	base := fset.File(f.Pos()).Base()
+	offset := func(p token.Pos) int { return int(p) - base }
+
+	// Entities whose constants layer is off keep their constant but do not
+	// get a new one.
+	want := map[string]string{}
+	known := map[string]bool{}
+	for _, e := range entities {
+		name, value := paramConstant(e, cfg)
+		known[name] = true
+		if cfg.selectsFor(e, LayerConstants) {
+			want[name] = value
+		}
+	}
+	if !exists && len(want) == 0 {
+		return nil, constantsChange{}, nil
+	}
+
+	block := findConstantsBlock(f, cfg)
+
+	// Constants declared outside the managed block are left alone and never
+	// declared a second time.
+	declared := map[string]bool{}
+	for _, decl := range f.Decls {
+		gd, ok := decl.(*ast.GenDecl)
+		if !ok || gd.Tok != token.CONST || gd == block {
+			continue
+		}
+		for _, spec := range gd.Specs {
+			for _, n := range spec.(*ast.ValueSpec).Names {
+				declared[n.Name] = true
+			}
+		}
+	}
+
+	var entries []constEntry
+	var trailing string
+	var added, removed []string
+	sortable := true
+
+	if block != nil {
+		attached := map[*ast.CommentGroup]bool{}
+		for _, spec := range block.Specs {
+			vs := spec.(*ast.ValueSpec)
+			if vs.Doc != nil {
+				attached[vs.Doc] = true
+			}
+			if vs.Comment != nil {
+				attached[vs.Comment] = true
+			}
+		}
+		// Comments separated from the next constant by a blank line still
+		// describe it: they move along with it when the block is sorted.
+		var floating []*ast.CommentGroup
+		for _, cg := range f.Comments {
+			if cg.Pos() > block.Lparen && cg.End() < block.Rparen && !attached[cg] {
+				floating = append(floating, cg)
+			}
+		}
+
+		for _, spec := range block.Specs {
+			vs := spec.(*ast.ValueSpec)
+			if len(vs.Values) == 0 {
+				// iota style blocks depend on their order
+				sortable = false
+			}
+			start, end := vs.Pos(), vs.End()
+			if vs.Doc != nil {
+				start = vs.Doc.Pos()
+			}
+			if vs.Comment != nil {
+				end = vs.Comment.End()
+			}
+
+			name := vs.Names[0].Name
+			if cfg.PruneConstants && len(vs.Names) == 1 && ownsConstant(vs) && isManagedConstant(name, cfg) {
+				if !known[name] {
+					// Its floating comments go to the next constant.
+					removed = append(removed, name)
+					continue
+				}
+			}
+			var lead string
+			for len(floating) > 0 && floating[0].End() < start {
+				lead += string(src[offset(floating[0].Pos()):offset(floating[0].End())]) + "\n\n"
+				floating = floating[1:]
+			}
+			entries = append(entries, constEntry{name: name, src: lead + string(src[offset(start):offset(end)])})
+			for _, n := range vs.Names {
+				declared[n.Name] = true
+			}
+		}
+		for _, cg := range floating {
+			trailing += "\n\t" + string(src[offset(cg.Pos()):offset(cg.End())]) + "\n"
+		}
+	}
+
+	for name, value := range want {
+		if declared[name] {
+			continue
+		}
+		entries = append(entries, constEntry{name: name, src: fmt.Sprintf("%s = %q // %s", name, value, constantMarker)})
+		added = append(added, name)
+	}
+	sort.Strings(added)
+	sort.Strings(removed)
+
+	if sortable {
+		sort.SliceStable(entries, func(i, j int) bool { return entries[i].name < entries[j].name })
+	}
+
+	var body bytes.Buffer
+	if len(entries) > 0 || trailing != "" {
+		body.WriteString("const (\n")
+		for _, e := range entries {
+			body.WriteString(e.src + "\n")
+		}
+		body.WriteString(trailing)
+		body.WriteString(")")
+	}
+
+	var out bytes.Buffer
+	switch {
+	case block != nil && body.Len() > 0:
+		out.Write(src[:offset(block.Pos())])
+		out.Write(body.Bytes())
+		out.Write(src[offset(block.End()):])
+	case block != nil:
+		// Every constant was pruned: drop the block along with its doc.
+		start := block.Pos()
+		if block.Doc != nil {
+			start = block.Doc.Pos()
+		}
+		out.Write(src[:offset(start)])
+		out.Write(src[offset(block.End()):])
+	case body.Len() > 0:
+		out.Write(bytes.TrimRight(src, "\n"))
+		out.WriteString("\n\n// " + constantsDoc + "\n")
+		out.Write(body.Bytes())
+		out.WriteString("\n")
+	default:
+		out.Write(src)
+	}
+
+	formatted, err := format.Source(out.Bytes())
+	if err != nil {
+		return nil, constantsChange{}, fmt.Errorf("format constants file: %w", err)
+	}
+
+	var change constantsChange
+	if exists && bytes.Equal(formatted, src) {
+		for name := range want {
+			change.existing = append(change.existing, name)
+		}
+		sort.Strings(change.existing)
+		return nil, change, nil
+	}
+
+	change.created = !exists
+	change.added = added
+	change.removed = removed
+	return &File{Path: cfg.constantsFile(), Content: formatted, Layer: "constants", Merged: true}, change, nil
+}
+
+// constantsChange describes what renderConstants did to the constants file.
+type constantsChange struct {
+	created  bool
+	added    []string
+	removed  []string
+	existing []string // wanted constants that were already declared
+}
+
+func (c constantsChange) message(path string) string {
+	if c.created {
+		return fmt.Sprintf("✅ Created constants file: %s", path)
+	}
+	var changes []string
+	if len(c.added) > 0 {
+		changes = append(changes, "added "+strings.Join(c.added, ", "))
+	}
+	if len(c.removed) > 0 {
+		changes = append(changes, "removed "+strings.Join(c.removed, ", "))
+	}
+	if len(changes) == 0 {
+		changes = append(changes, "sorted")
+	}
+	return fmt.Sprintf("✅ Updated constants: %s (%s)", path, strings.Join(changes, "; "))
+}
+
+// findConstantsBlock returns the parenthesized const block dashgen manages:
+// the first one holding a constant that follows the naming scheme, or else
+// the first one documented as API parameter constants.
+func findConstantsBlock(f *ast.File, cfg Config) *ast.GenDecl {
+	var documented *ast.GenDecl
+	for _, decl := range f.Decls {
+		gd, ok := decl.(*ast.GenDecl)
+		if !ok || gd.Tok != token.CONST || !gd.Lparen.IsValid() {
+			continue
+		}
+		for _, spec := range gd.Specs {
+			for _, n := range spec.(*ast.ValueSpec).Names {
+				if isManagedConstant(n.Name, cfg) {
+					return gd
+				}
+			}
+		}
+		if documented == nil && gd.Doc != nil && strings.Contains(gd.Doc.Text(), constantsDoc) {
+			documented = gd
+		}
+	}
+	return documented
+}
@@ -0,0 +1,80 @@
+package generator
+
+import (
+	"fmt"
+
+	"github.com/gotech-hub/dashgen/internal/parser"
+)
+
+// cursorField is a field of the sort order of cursor pagination.
+type cursorField struct {
+	Field    string // Go field name
+	JSONName string
+	BSONName string
+	Column   string // column of the SQL backends
+	Type     string // Go type
+	Kind     string // string, int, float, bool or time
+	Desc     bool
+}
+
+// cursorFields returns the sort order of cursor pagination: the fields of
+// the @index marked cursor, followed by the key field unless the index
+// already holds it. Without such an index pages follow the key field. The
+// fields must be non-pointer strings, numbers, bools or times, so that
+// every document has a value to compare.
+func cursorFields(fields []parser.Field, indexes []parser.Index, key string) ([]cursorField, error) {
+	byBSON := map[string]filterField{}
+	var keyField *filterField
+	for _, f := range typedFilterFields(fields) {
+		if isPointer(fieldByName(fields, f.Field).Type) {
+			continue
+		}
+		byBSON[f.BSONName] = f
+		if f.Field == key {
+			keyField = &f
+		}
+	}
+	if keyField == nil {
+		return nil, fmt.Errorf("cursor: key field %s is not a stored string, number, bool or time", key)
+	}
+	columns := map[string]string{}
+	for _, c := range sqlColumns(fields) {
+		columns[c.Field] = c.Name
+	}
+	field := func(f filterField, desc bool) cursorField {
+		return cursorField{
+			Field:    f.Field,
+			JSONName: f.JSONName,
+			BSONName: f.BSONName,
+			Column:   columns[f.Field],
+			Type:     f.Type,
+			Kind:     f.Kind,
+			Desc:     desc,
+		}
+	}
+
+	var out []cursorField
+	declared := false
+	for _, idx := range indexes {
+		if !idx.Cursor {
+			continue
+		}
+		if declared {
+			return nil, fmt.Errorf("@index cursor: only one index can be the cursor index")
+		}
+		declared = true
+		for _, f := range idx.Fields {
+			ff, ok := byBSON[f.Name]
+			if !ok || f.Type != "" {
+				return nil, fmt.Errorf("@index cursor: %s is not a non-pointer string, number, bool or time field", f.Name)
+			}
+			out = append(out, field(ff, f.Direction < 0))
+		}
+	}
+	for _, f := range out {
+		if f.Field == key {
+			return out, nil
+		}
+	}
+	return append(out, field(*keyField, false)), nil
+}
@@ -0,0 +1,76 @@
+package generator
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/gotech-hub/dashgen/internal/parser"
+)
+
+func TestCursorFields(t *testing.T) {
+	fields := append(append([]parser.Field(nil), widgetFields...),
+		parser.Field{Name: "CreatedAt", Type: "time.Time", BSONTag: "created_at"},
+		parser.Field{Name: "DeletedAt", Type: "*time.Time", BSONTag: "deleted_at"},
+	)
+	cursor := func(fields ...parser.IndexField) parser.Index {
+		return parser.Index{Fields: fields, Cursor: true}
+	}
+	tests := []struct {
+		name    string
+		indexes []parser.Index
+		want    []string // Go field names, "-" prefixed when descending
+		err     string
+	}{
+		{name: "key field without a cursor index", want: []string{"WidgetID"}},
+		{name: "other indexes are ignored", indexes: []parser.Index{{Fields: []parser.IndexField{{Name: "price", Direction: -1}}}}, want: []string{"WidgetID"}},
+		{
+			name:    "cursor index followed by the key field",
+			indexes: []parser.Index{cursor(parser.IndexField{Name: "created_at", Direction: -1}, parser.IndexField{Name: "price", Direction: 1})},
+			want:    []string{"-CreatedAt", "Price", "WidgetID"},
+		},
+		{
+			name:    "cursor index holding the key field",
+			indexes: []parser.Index{cursor(parser.IndexField{Name: "status", Direction: 1}, parser.IndexField{Name: "widget_id", Direction: -1})},
+			want:    []string{"Status", "-WidgetID"},
+		},
+		{
+			name:    "two cursor indexes",
+			indexes: []parser.Index{cursor(parser.IndexField{Name: "status", Direction: 1}), cursor(parser.IndexField{Name: "price", Direction: 1})},
+			err:     "only one index can be the cursor index",
+		},
+		{name: "pointer field", indexes: []parser.Index{cursor(parser.IndexField{Name: "deleted_at", Direction: 1})}, err: "deleted_at is not a non-pointer"},
+		{name: "slice field", indexes: []parser.Index{cursor(parser.IndexField{Name: "tags", Direction: 1})}, err: "tags is not a non-pointer"},
+		{name: "unknown field", indexes: []parser.Index{cursor(parser.IndexField{Name: "color", Direction: 1})}, err: "color is not a non-pointer"},
+		{name: "text index", indexes: []parser.Index{cursor(parser.IndexField{Name: "status", Direction: 1, Type: "text"})}, err: "status is not a non-pointer"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := cursorFields(fields, tt.indexes, "WidgetID")
+			if tt.err != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.err) {
+					t.Fatalf("cursorFields() error = %v, want %q", err, tt.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			var names []string
+			for _, f := range got {
+				name := f.Field
+				if f.Desc {
+					name = "-" + name
+				}
+				names = append(names, name)
+			}
+			if !reflect.DeepEqual(names, tt.want) {
+				t.Errorf("cursorFields() = %v, want %v", names, tt.want)
+			}
+		})
+	}
+
+	if _, err := cursorFields(fields, nil, "Note"); err == nil || !strings.Contains(err.Error(), "key field Note") {
+		t.Errorf("cursorFields() with a pointer key field: error = %v", err)
+	}
+}
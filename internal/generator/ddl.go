@@ -0,0 +1,360 @@
+package generator
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/gotech-hub/dashgen/internal/naming"
+	"github.com/gotech-hub/dashgen/internal/parser"
+)
+
+// SQL dialects of the SQL backends.
+const (
+	dialectPostgres = "postgres"
+	dialectSQLite   = "sqlite"
+)
+
+// deletedAtColumn marks soft-deleted rows of SQL tables; live rows hold
+// NULL.
+const deletedAtColumn = "deleted_at"
+
+// sqlColumn is the column a struct field is stored in by the SQL backends.
+type sqlColumn struct {
+	Field    string // Go field name
+	Name     string // column name
+	JSONName string // name in JSON, used to translate query filters
+	GoType   string
+	JSON     bool // stored as JSON: slices, maps, structs and other named types
+	Nullable bool // pointer fields and []byte, whose nil is written as NULL
+	Primary  bool // the field tagged bson:"_id"
+}
+
+// sqlColumns returns the columns of fields in declaration order. Fields
+// tagged bson:"-" are not stored.
+func sqlColumns(fields []parser.Field) []sqlColumn {
+	var cols []sqlColumn
+	for _, f := range fields {
+		name, _, _ := strings.Cut(f.BSONTag, ",")
+		if name == "-" {
+			continue
+		}
+		primary := name == "_id"
+		switch {
+		case primary:
+			name = "id"
+		case name == "":
+			name = naming.Snake(f.Name)
+		}
+		jsonName, _, _ := strings.Cut(f.JSONTag, ",")
+		if jsonName == "" || jsonName == "-" {
+			jsonName = f.Name
+		}
+		base := strings.TrimPrefix(f.Type, "*")
+		cols = append(cols, sqlColumn{
+			Field:    f.Name,
+			Name:     name,
+			JSONName: jsonName,
+			GoType:   f.Type,
+			JSON:     sqlBaseType(dialectPostgres, base) == "",
+			Nullable: isPointer(f.Type) || f.Type == "[]byte",
+			Primary:  primary,
+		})
+	}
+	return cols
+}
+
+// sqlColumnOf returns the column of the field named field.
+func sqlColumnOf(fields []parser.Field, field string) (string, error) {
+	for _, c := range sqlColumns(fields) {
+		if c.Field == field {
+			return c.Name, nil
+		}
+	}
+	return "", fmt.Errorf("no stored field %s", field)
+}
+
+// sqlBaseType maps a Go type without pointer to a column type, or returns
+// "" for types stored as JSON. SQLite only has a few storage classes; its
+// BOOLEAN and DATETIME are declared so that drivers scan them back into
+// bool and time.Time.
+func sqlBaseType(dialect, typ string) string {
+	var postgres, sqlite string
+	switch typ {
+	case "string":
+		postgres, sqlite = "TEXT", "TEXT"
+	case "bool":
+		postgres, sqlite = "BOOLEAN", "BOOLEAN"
+	case "int8", "int16", "uint8", "byte":
+		postgres, sqlite = "SMALLINT", "INTEGER"
+	case "int32", "rune", "uint16":
+		postgres, sqlite = "INTEGER", "INTEGER"
+	case "int", "int64", "uint", "uint32", "uint64":
+		postgres, sqlite = "BIGINT", "INTEGER"
+	case "float32":
+		postgres, sqlite = "REAL", "REAL"
+	case "float64":
+		postgres, sqlite = "DOUBLE PRECISION", "REAL"
+	case "[]byte":
+		postgres, sqlite = "BYTEA", "BLOB"
+	case "time.Time":
+		postgres, sqlite = "TIMESTAMPTZ", "DATETIME"
+	}
+	if dialect == dialectSQLite {
+		return sqlite
+	}
+	return postgres
+}
+
+// sqlType returns the column type of c, including its constraints.
+func sqlType(dialect string, c sqlColumn) string {
+	typ := sqlBaseType(dialect, strings.TrimPrefix(c.GoType, "*"))
+	if c.JSON {
+		typ = "JSONB"
+		if dialect == dialectSQLite {
+			typ = "TEXT"
+		}
+	}
+	switch {
+	case c.Primary:
+		typ += " PRIMARY KEY"
+	case !c.Nullable:
+		typ += " NOT NULL"
+	}
+	return typ
+}
+
+// sqlSchema returns the statements creating the table of an entity and
+// its indexes: the field indexes, the compound indexes and a unique index
+// on the key column. Unique indexes only cover live rows, so a deleted
+// row does not block re-creating its key, and partial or sparse indexes
+// only cover the rows matching their conditions. Table, column and index
+// names are quoted. Statements are idempotent.
+func sqlSchema(dialect, table string, fields []parser.Field, indexes []parser.Index, key string) ([]string, error) {
+	cols := sqlColumns(fields)
+	byName := map[string]sqlColumn{}
+	byField := map[string]sqlColumn{}
+	var defs []string
+	for _, c := range cols {
+		byName[c.Name] = c
+		byField[c.Field] = c
+		defs = append(defs, sqlQuote(c.Name)+" "+sqlType(dialect, c))
+	}
+	if _, ok := byName[deletedAtColumn]; !ok {
+		defs = append(defs, sqlQuote(deletedAtColumn)+" "+sqlBaseType(dialect, "time.Time"))
+	}
+	stmts := []string{fmt.Sprintf("CREATE TABLE IF NOT EXISTS %s (\n\t%s\n)", sqlQuote(table), strings.Join(defs, ",\n\t"))}
+
+	seen := map[string]bool{}
+	add := func(name string, unique bool, using, exprs, where string) {
+		if seen[name] {
+			return
+		}
+		seen[name] = true
+		stmt := "CREATE INDEX IF NOT EXISTS "
+		if unique {
+			stmt = "CREATE UNIQUE INDEX IF NOT EXISTS "
+			where = joinConditions(where, sqlQuote(deletedAtColumn)+" IS NULL")
+		}
+		stmt += sqlQuote(name) + " ON " + sqlQuote(table) + using + " (" + exprs + ")"
+		if where != "" {
+			stmt += " WHERE " + where
+		}
+		stmts = append(stmts, stmt)
+	}
+
+	keyCol, err := sqlColumnOf(fields, key)
+	if err != nil {
+		return nil, err
+	}
+	add(table+"_"+keyCol+"_key", true, "", sqlQuote(keyCol), "")
+
+	for _, f := range fields {
+		c, ok := byField[f.Name]
+		if !ok {
+			continue
+		}
+		switch f.Index {
+		case "":
+		case "1":
+			add(table+"_"+c.Name+"_idx", false, "", sqlQuote(c.Name), "")
+		case "-1":
+			add(table+"_"+c.Name+"_idx", false, "", sqlQuote(c.Name)+" DESC", "")
+		case "unique":
+			add(table+"_"+c.Name+"_key", true, "", sqlQuote(c.Name), "")
+		case "sparse":
+			add(table+"_"+c.Name+"_idx", false, "", sqlQuote(c.Name), sqlQuote(c.Name)+" IS NOT NULL")
+		case "text":
+			using, exprs := textIndex(dialect, c.Name)
+			add(table+"_"+c.Name+"_text_idx", false, using, exprs, "")
+		default:
+			return nil, fmt.Errorf("field %s: index %q is not supported by SQL backends", c.Field, f.Index)
+		}
+	}
+
+	for _, idx := range indexes {
+		var names, exprs, text, notNull []string
+		for _, f := range idx.Fields {
+			if _, ok := byName[f.Name]; !ok {
+				return nil, fmt.Errorf("@index: no column %s", f.Name)
+			}
+			names = append(names, f.Name)
+			switch {
+			case f.Type == "text":
+				text = append(text, f.Name)
+			case f.Type != "":
+				return nil, fmt.Errorf("@index: index type %q of %s is not supported by SQL backends", f.Type, f.Name)
+			case f.Direction == -1:
+				exprs = append(exprs, sqlQuote(f.Name)+" DESC")
+			default:
+				exprs = append(exprs, sqlQuote(f.Name))
+			}
+			notNull = append(notNull, sqlQuote(f.Name)+" IS NOT NULL")
+		}
+		var where string
+		if idx.Sparse {
+			where = strings.Join(notNull, " AND ")
+		}
+		for _, cond := range idx.Partial {
+			if _, ok := byName[cond.Field]; !ok {
+				return nil, fmt.Errorf("@index: no column %s", cond.Field)
+			}
+			where = joinConditions(where, sqlCondition(cond))
+		}
+		if len(text) > 0 {
+			name := idx.Name
+			if name == "" {
+				name = table + "_" + strings.Join(text, "_") + "_text_idx"
+			}
+			using, exprs := textIndex(dialect, text...)
+			add(name, false, using, exprs, where)
+		}
+		if len(exprs) > 0 {
+			name := idx.Name
+			switch {
+			case name != "" && len(text) > 0:
+				name += "_btree"
+			case name == "" && idx.Unique:
+				name = table + "_" + strings.Join(names, "_") + "_key"
+			case name == "":
+				name = table + "_" + strings.Join(names, "_") + "_idx"
+			}
+			add(name, idx.Unique, "", strings.Join(exprs, ", "), where)
+		}
+	}
+	return stmts, nil
+}
+
+// textIndex returns the method and expressions of a text index over
+// columns, which are quoted. PostgreSQL indexes their full-text search vector; SQLite has no
+// such index outside of FTS tables, so it gets a plain index that serves
+// equality and prefix matches.
+func textIndex(dialect string, columns ...string) (using, exprs string) {
+	var quoted []string
+	for _, c := range columns {
+		quoted = append(quoted, sqlQuote(c))
+	}
+	if dialect == dialectSQLite {
+		return "", strings.Join(quoted, ", ")
+	}
+	var parts []string
+	for _, c := range quoted {
+		parts = append(parts, "coalesce("+c+", '')")
+	}
+	return " USING GIN", "to_tsvector('simple', " + strings.Join(parts, " || ' ' || ") + ")"
+}
+
+// sqlCondition translates a partial index condition into SQL. true, false,
+// null and numbers are written as such; other values are strings.
+func sqlCondition(cond parser.Condition) string {
+	switch v := cond.Value; {
+	case v == "null":
+		return sqlQuote(cond.Field) + " IS NULL"
+	case v == "true" || v == "false":
+		return sqlQuote(cond.Field) + " = " + strings.ToUpper(v)
+	case isNumber(v):
+		return sqlQuote(cond.Field) + " = " + v
+	default:
+		return sqlQuote(cond.Field) + " = '" + strings.ReplaceAll(v, "'", "''") + "'"
+	}
+}
+
+// numberPattern matches the decimal numbers of partial index conditions.
+var numberPattern = regexp.MustCompile(`^-?[0-9]+(\.[0-9]+)?$`)
+
+// isNumber reports whether s is a decimal number.
+func isNumber(s string) bool {
+	return numberPattern.MatchString(s)
+}
+
+// joinConditions joins the non-empty SQL conditions with AND.
+func joinConditions(conds ...string) string {
+	var nonEmpty []string
+	for _, c := range conds {
+		if c != "" {
+			nonEmpty = append(nonEmpty, c)
+		}
+	}
+	return strings.Join(nonEmpty, " AND ")
+}
+
+// Arg returns the Go expression writing the column from the struct
+// variable v.
+func (c sqlColumn) Arg(v string) string {
+	if c.JSON {
+		return "jsonValue{" + v + "." + c.Field + "}"
+	}
+	return v + "." + c.Field
+}
+
+// Dest returns the Go expression scanning the column into the struct
+// variable v.
+func (c sqlColumn) Dest(v string) string {
+	if c.JSON {
+		return "jsonDest{&" + v + "." + c.Field + "}"
+	}
+	return "&" + v + "." + c.Field
+}
+
+// sqlQuote quotes an SQL identifier, doubling embedded quotes, so that
+// names such as "order" or "user" are not read as keywords.
+func sqlQuote(name string) string {
+	return `"` + strings.ReplaceAll(name, `"`, `""`) + `"`
+}
+
+// sqlIdent returns the quoted identifier name as it is written inside an
+// interpreted Go string literal of the generated code.
+func sqlIdent(name string) string {
+	q := strconv.Quote(sqlQuote(name))
+	return q[1 : len(q)-1]
+}
+
+// sqlNames returns the comma-separated quoted names of cols, for a Go
+// string literal of the generated code.
+func sqlNames(cols []sqlColumn) string {
+	names := make([]string, len(cols))
+	for i, c := range cols {
+		names[i] = sqlIdent(c.Name)
+	}
+	return strings.Join(names, ", ")
+}
+
+// sqlParam returns the prefix of the numbered placeholders of a dialect:
+// $N for PostgreSQL and ?N for SQLite. Both number the arguments, so a
+// query can be built the same way for either.
+func sqlParam(dialect string) string {
+	if dialect == dialectSQLite {
+		return "?"
+	}
+	return "$"
+}
+
+// sqlPlaceholders returns the placeholders 1 to n with the prefix p.
+func sqlPlaceholders(p string, n int) string {
+	ps := make([]string, n)
+	for i := range ps {
+		ps[i] = fmt.Sprintf("%s%d", p, i+1)
+	}
+	return strings.Join(ps, ", ")
+}
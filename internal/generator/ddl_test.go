@@ -0,0 +1,164 @@
+package generator
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/gotech-hub/dashgen/internal/parser"
+)
+
+var widgetFields = []parser.Field{
+	{Name: "ID", Type: "string", BSONTag: "_id"},
+	{Name: "WidgetID", Type: "string", BSONTag: "widget_id"},
+	{Name: "SKU", Type: "string", BSONTag: "sku", Index: "unique"},
+	{Name: "Status", Type: "string", BSONTag: "status"},
+	{Name: "Price", Type: "float64", BSONTag: "price"},
+	{Name: "Tags", Type: "[]string", BSONTag: "tags"},
+	{Name: "Note", Type: "*string", BSONTag: "note,omitempty"},
+}
+
+const widgetTable = `CREATE TABLE IF NOT EXISTS "widgets" (
+	"id" TEXT PRIMARY KEY,
+	"widget_id" TEXT NOT NULL,
+	"sku" TEXT NOT NULL,
+	"status" TEXT NOT NULL,
+	"price" DOUBLE PRECISION NOT NULL,
+	"tags" JSONB NOT NULL,
+	"note" TEXT,
+	"deleted_at" TIMESTAMPTZ
+)`
+
+func TestSQLSchema(t *testing.T) {
+	tests := []struct {
+		name    string
+		dialect string
+		table   string // "widgets" if empty
+		fields  []parser.Field
+		indexes []parser.Index
+		want    []string
+	}{
+		{
+			name:    "unique indexes only cover live rows",
+			dialect: dialectPostgres,
+			fields:  widgetFields,
+			want: []string{
+				widgetTable,
+				`CREATE UNIQUE INDEX IF NOT EXISTS "widgets_widget_id_key" ON "widgets" ("widget_id") WHERE "deleted_at" IS NULL`,
+				`CREATE UNIQUE INDEX IF NOT EXISTS "widgets_sku_key" ON "widgets" ("sku") WHERE "deleted_at" IS NULL`,
+			},
+		},
+		{
+			name:    "partial and sparse indexes",
+			dialect: dialectPostgres,
+			fields:  widgetFields,
+			indexes: []parser.Index{
+				{Fields: []parser.IndexField{{Name: "status", Direction: 1}, {Name: "price", Direction: -1}}, Partial: []parser.Condition{{Field: "status", Value: "active"}}},
+				{Fields: []parser.IndexField{{Name: "note", Direction: 1}}, Unique: true, Name: "widgets_free_note", Partial: []parser.Condition{{Field: "price", Value: "0"}}},
+				{Fields: []parser.IndexField{{Name: "note", Direction: 1}}, Sparse: true},
+			},
+			want: []string{
+				widgetTable,
+				`CREATE UNIQUE INDEX IF NOT EXISTS "widgets_widget_id_key" ON "widgets" ("widget_id") WHERE "deleted_at" IS NULL`,
+				`CREATE UNIQUE INDEX IF NOT EXISTS "widgets_sku_key" ON "widgets" ("sku") WHERE "deleted_at" IS NULL`,
+				`CREATE INDEX IF NOT EXISTS "widgets_status_price_idx" ON "widgets" ("status", "price" DESC) WHERE "status" = 'active'`,
+				`CREATE UNIQUE INDEX IF NOT EXISTS "widgets_free_note" ON "widgets" ("note") WHERE "price" = 0 AND "deleted_at" IS NULL`,
+				`CREATE INDEX IF NOT EXISTS "widgets_note_idx" ON "widgets" ("note") WHERE "note" IS NOT NULL`,
+			},
+		},
+		{
+			name:    "reserved words are quoted, in WHERE clauses too",
+			dialect: dialectPostgres,
+			table:   "user",
+			fields: []parser.Field{
+				{Name: "ID", Type: "string", BSONTag: "_id"},
+				{Name: "WidgetID", Type: "string", BSONTag: "widget_id"},
+				{Name: "Order", Type: "int", BSONTag: "order", Index: "-1"},
+				{Name: "Say", Type: "string", BSONTag: `say"hi`},
+			},
+			indexes: []parser.Index{
+				{Fields: []parser.IndexField{{Name: "order", Direction: 1}, {Name: `say"hi`, Type: "text"}}, Unique: true},
+				{Fields: []parser.IndexField{{Name: "widget_id", Direction: 1}}, Name: "by_order", Partial: []parser.Condition{{Field: "order", Value: "0"}, {Field: `say"hi`, Value: "null"}}},
+			},
+			want: []string{
+				`CREATE TABLE IF NOT EXISTS "user" (
+	"id" TEXT PRIMARY KEY,
+	"widget_id" TEXT NOT NULL,
+	"order" BIGINT NOT NULL,
+	"say""hi" TEXT NOT NULL,
+	"deleted_at" TIMESTAMPTZ
+)`,
+				`CREATE UNIQUE INDEX IF NOT EXISTS "user_widget_id_key" ON "user" ("widget_id") WHERE "deleted_at" IS NULL`,
+				`CREATE INDEX IF NOT EXISTS "user_order_idx" ON "user" ("order" DESC)`,
+				`CREATE INDEX IF NOT EXISTS "user_say""hi_text_idx" ON "user" USING GIN (to_tsvector('simple', coalesce("say""hi", '')))`,
+				`CREATE UNIQUE INDEX IF NOT EXISTS "user_order_say""hi_key" ON "user" ("order") WHERE "deleted_at" IS NULL`,
+				`CREATE INDEX IF NOT EXISTS "by_order" ON "user" ("widget_id") WHERE "order" = 0 AND "say""hi" IS NULL`,
+			},
+		},
+		{
+			name:    "declared deleted_at column",
+			dialect: dialectSQLite,
+			fields: []parser.Field{
+				{Name: "ID", Type: "string", BSONTag: "_id"},
+				{Name: "WidgetID", Type: "string", BSONTag: "widget_id"},
+				{Name: "DeletedAt", Type: "*time.Time", BSONTag: "deleted_at"},
+			},
+			want: []string{
+				`CREATE TABLE IF NOT EXISTS "widgets" (
+	"id" TEXT PRIMARY KEY,
+	"widget_id" TEXT NOT NULL,
+	"deleted_at" DATETIME
+)`,
+				`CREATE UNIQUE INDEX IF NOT EXISTS "widgets_widget_id_key" ON "widgets" ("widget_id") WHERE "deleted_at" IS NULL`,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			table := tt.table
+			if table == "" {
+				table = "widgets"
+			}
+			got, err := sqlSchema(tt.dialect, table, tt.fields, tt.indexes, "WidgetID")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("sqlSchema() =\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(tt.want, "\n"))
+			}
+		})
+	}
+}
+
+func TestSQLSchemaErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		indexes []parser.Index
+		want    string
+	}{
+		{"unknown column", []parser.Index{{Fields: []parser.IndexField{{Name: "color", Direction: 1}}}}, "no column color"},
+		{"unknown partial column", []parser.Index{{Fields: []parser.IndexField{{Name: "sku", Direction: 1}}, Partial: []parser.Condition{{Field: "color", Value: "red"}}}}, "no column color"},
+		{"unsupported type", []parser.Index{{Fields: []parser.IndexField{{Name: "status", Type: "2dsphere"}}}}, `index type "2dsphere"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := sqlSchema(dialectPostgres, "widgets", widgetFields, tt.indexes, "WidgetID")
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("sqlSchema() error = %v, want %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestSQLIdent(t *testing.T) {
+	tests := map[string]string{
+		"order":   `\"order\"`,
+		`say"hi`:  `\"say\"\"hi\"`,
+		"user_id": `\"user_id\"`,
+	}
+	for name, want := range tests {
+		if got := sqlIdent(name); got != want {
+			t.Errorf("sqlIdent(%q) = %s, want %s", name, got, want)
+		}
+	}
+}
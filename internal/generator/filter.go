@@ -0,0 +1,53 @@
+package generator
+
+import (
+	"strings"
+
+	"github.com/gotech-hub/dashgen/internal/parser"
+)
+
+// filterField is a field the typed filter of an entity has methods for.
+type filterField struct {
+	Field    string // Go field name
+	JSONName string // name in the JSON form of filters
+	BSONName string
+	Type     string // Go type of the values, without pointer
+	Kind     string // string, int, float, bool or time
+}
+
+// typedFilterFields returns the stored fields whose type, once dereferenced, is
+// a string, number, bool or time.Time, in declaration order. Slices, maps
+// and named types cannot be filtered on.
+func typedFilterFields(fields []parser.Field) []filterField {
+	var out []filterField
+	for _, m := range memoryFields(fields) {
+		typ := strings.TrimPrefix(fieldByName(fields, m.Field).Type, "*")
+		switch kind := typeKind(typ); kind {
+		case "string", "int", "float", "bool", "time":
+			out = append(out, filterField{
+				Field:    m.Field,
+				JSONName: m.JSONName,
+				BSONName: m.BSONName,
+				Type:     typ,
+				Kind:     kind,
+			})
+		}
+	}
+	return out
+}
+
+// Ops returns the operators the JSON form of a filter accepts on the
+// field, space separated. They match the methods of the filter.
+func (f filterField) Ops() string {
+	switch f.Kind {
+	case "string":
+		return "$eq $ne $in $nin $contains"
+	case "int", "float":
+		return "$eq $ne $in $nin $gt $gte $lt $lte"
+	case "bool":
+		return "$eq"
+	case "time":
+		return "$gt $gte $lt $lte"
+	}
+	return ""
+}
@@ -0,0 +1,75 @@
+package generator
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/gotech-hub/dashgen/internal/templates"
+)
+
+// HTTP frameworks the api and routes layers can target.
+const (
+	FrameworkSDK     = "sdk"
+	FrameworkNetHTTP = "nethttp"
+	FrameworkChi     = "chi"
+	FrameworkGin     = "gin"
+	FrameworkEcho    = "echo"
+)
+
+// DefaultFramework is used when Config.Framework is empty.
+const DefaultFramework = FrameworkSDK
+
+// Packages of the project that replace the go-sdk modules with every
+// framework but sdk, relative to the project root. "dashgen init" writes
+// them.
+const (
+	CommonDir     = "common"              // response and query types of the actions, handlers and client
+	CollectionDir = "internal/collection" // collections of the MongoDB model layer
+)
+
+// framework describes how the generated handlers look for a framework.
+type framework struct {
+	routes     string // source of the routes template
+	request    string // type of the handlers' req parameter
+	responder  string // type of the handlers' res parameter
+	context    string // expression of the request context in a handler
+	common     string // import path of the response types; "" is CommonDir of the project
+	collection string // import path of the MongoDB collections; "" is CollectionDir of the project
+}
+
+var frameworks = map[string]framework{
+	FrameworkSDK:     {routes: templates.Routes, request: "request.APIRequest", responder: "responder.APIResponder", context: "requestContext(req)", common: "gitlab.silvertiger.tech/go-sdk/go-common/common", collection: "gitlab.silvertiger.tech/go-sdk/go-mongodb/collection"},
+	FrameworkNetHTTP: {routes: templates.RoutesNetHTTP, request: "Request", responder: "Responder", context: "req.Context()"},
+	FrameworkChi:     {routes: templates.RoutesChi, request: "Request", responder: "Responder", context: "req.Context()"},
+	FrameworkGin:     {routes: templates.RoutesGin, request: "Request", responder: "Responder", context: "req.Context()"},
+	FrameworkEcho:    {routes: templates.RoutesEcho, request: "Request", responder: "Responder", context: "req.Context()"},
+}
+
+// Frameworks returns the supported frameworks, sorted.
+func Frameworks() []string {
+	var names []string
+	for name := range frameworks {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// framework returns the name and description of the configured framework.
+func (cfg Config) framework() (string, framework, error) {
+	name := cfg.Framework
+	if name == "" {
+		name = DefaultFramework
+	}
+	fw, ok := frameworks[name]
+	if !ok {
+		return "", framework{}, fmt.Errorf("unknown framework %q (available: %s)", name, strings.Join(Frameworks(), ", "))
+	}
+	return name, fw, nil
+}
+
+// routesTemplate names the built-in routes template of a framework.
+func routesTemplate(name string) string {
+	return "routes/" + name
+}
@@ -0,0 +1,271 @@
+package generator
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"text/template"
+
+	"github.com/gotech-hub/dashgen/internal/naming"
+	"github.com/gotech-hub/dashgen/internal/parser"
+)
+
+// templateFuncs returns the functions available to built-in and user
+// templates. The README documents every one of them; keep both in sync.
+func templateFuncs() template.FuncMap {
+	return template.FuncMap{
+		// case conversions
+		"lower":     strings.ToLower,
+		"upper":     strings.ToUpper,
+		"snake":     naming.Snake,
+		"kebab":     naming.Kebab,
+		"camel":     naming.Camel,
+		"pascal":    naming.Pascal,
+		"screaming": naming.Screaming,
+		"plural":    naming.Plural,
+		"singular":  naming.Singular,
+
+		// fields
+		"field":      fieldByName,
+		"fieldNames": fieldNames,
+		"withTag":    withTag,
+		"withoutTag": withoutTag,
+		"ofKind":     ofKind,
+		"required":   requiredFields,
+
+		// Go types
+		"kind":      typeKind,
+		"zeroValue": zeroValue,
+		"isPointer": isPointer,
+		"isSlice":   isSlice,
+		"elemType":  elemType,
+
+		// text
+		"indent":   indent,
+		"join":     join,
+		"quote":    strconv.Quote,
+		"quoteAll": quoteAll,
+
+		// generator helpers
+		"generateValidation": generateValidation,
+		"hasRequiredFields":  hasRequiredFields,
+		"generateIndexes":    generateIndexes,
+		"hasIndexes":         hasIndexes,
+
+		// SQL backends
+		"sqlColumns":      sqlColumns,
+		"sqlColumn":       sqlColumnOf,
+		"sqlSchema":       sqlSchema,
+		"sqlNames":        sqlNames,
+		"sqlIdent":        sqlIdent,
+		"sqlParam":        sqlParam,
+		"sqlPlaceholders": sqlPlaceholders,
+
+		// in-memory repository
+		"memoryFields":  memoryFields,
+		"memoryIndexes": memoryIndexes,
+
+		// typed filters and cursor pagination
+		"filterFields": typedFilterFields,
+		"cursorFields": cursorFields,
+
+		// replaced per run by entityFuncs
+		"entity":    func(string) (*parser.Entity, error) { return nil, nil },
+		"entities":  func() []parser.Entity { return nil },
+		"hasEntity": func(string) bool { return false },
+	}
+}
+
+// entityFuncs returns the lookups of the entities rendered in one run.
+func entityFuncs(entities []parser.Entity) template.FuncMap {
+	sorted := append([]parser.Entity(nil), entities...)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].Name < sorted[j].Name })
+
+	find := func(name string) *parser.Entity {
+		for i := range sorted {
+			if sorted[i].Name == name {
+				return &sorted[i]
+			}
+		}
+		return nil
+	}
+	return template.FuncMap{
+		"entity": func(name string) (*parser.Entity, error) {
+			if e := find(name); e != nil {
+				return e, nil
+			}
+			return nil, fmt.Errorf("unknown entity %q", name)
+		},
+		"entities":  func() []parser.Entity { return sorted },
+		"hasEntity": func(name string) bool { return find(name) != nil },
+	}
+}
+
+// fieldByName returns the field called name, or nil.
+func fieldByName(fields []parser.Field, name string) *parser.Field {
+	for i := range fields {
+		if fields[i].Name == name {
+			return &fields[i]
+		}
+	}
+	return nil
+}
+
+func fieldNames(fields []parser.Field) []string {
+	names := make([]string, len(fields))
+	for i, f := range fields {
+		names[i] = f.Name
+	}
+	return names
+}
+
+// fieldTag returns the value of one of the struct tags the parser keeps.
+func fieldTag(f parser.Field, tag string) (string, error) {
+	switch tag {
+	case "json":
+		return f.JSONTag, nil
+	case "bson":
+		return f.BSONTag, nil
+	case "validate":
+		return f.Validate, nil
+	case "index":
+		return f.Index, nil
+	}
+	return "", fmt.Errorf("unknown tag %q (want json, bson, validate or index)", tag)
+}
+
+// withTag returns the fields that carry a non-empty tag, e.g.
+// {{withTag .Fields "index"}}.
+func withTag(fields []parser.Field, tag string) ([]parser.Field, error) {
+	return filterFields(fields, func(f parser.Field) (bool, error) {
+		v, err := fieldTag(f, tag)
+		return v != "" && v != "-", err
+	})
+}
+
+// withoutTag returns the fields withTag leaves out.
+func withoutTag(fields []parser.Field, tag string) ([]parser.Field, error) {
+	return filterFields(fields, func(f parser.Field) (bool, error) {
+		v, err := fieldTag(f, tag)
+		return v == "" || v == "-", err
+	})
+}
+
+// ofKind returns the fields whose type is of the given kind.
+func ofKind(fields []parser.Field, kind string) ([]parser.Field, error) {
+	return filterFields(fields, func(f parser.Field) (bool, error) {
+		return typeKind(f.Type) == kind, nil
+	})
+}
+
+func requiredFields(fields []parser.Field) []parser.Field {
+	out, _ := filterFields(fields, func(f parser.Field) (bool, error) {
+		return strings.Contains(f.Validate, "required"), nil
+	})
+	return out
+}
+
+func filterFields(fields []parser.Field, keep func(parser.Field) (bool, error)) ([]parser.Field, error) {
+	var out []parser.Field
+	for _, f := range fields {
+		ok, err := keep(f)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			out = append(out, f)
+		}
+	}
+	return out, nil
+}
+
+// typeKind classifies a Go type as written in a struct field: pointer,
+// slice, map, string, bool, int, float, interface, time or named.
+func typeKind(typ string) string {
+	switch {
+	case strings.HasPrefix(typ, "*"):
+		return "pointer"
+	case strings.HasPrefix(typ, "[]"):
+		return "slice"
+	case strings.HasPrefix(typ, "map["):
+		return "map"
+	case typ == "time.Time":
+		return "time"
+	case typ == "interface{}" || typ == "any":
+		return "interface"
+	}
+	switch typ {
+	case "string":
+		return "string"
+	case "bool":
+		return "bool"
+	case "int", "int8", "int16", "int32", "int64",
+		"uint", "uint8", "uint16", "uint32", "uint64", "uintptr", "byte", "rune":
+		return "int"
+	case "float32", "float64":
+		return "float"
+	}
+	return "named"
+}
+
+// zeroValue returns the Go expression of the zero value of typ. The
+// underlying kind of named types is unknown, so their zero value is
+// spelled *new(T), which also holds for named strings and numbers.
+func zeroValue(typ string) string {
+	switch typeKind(typ) {
+	case "pointer", "slice", "map", "interface":
+		return "nil"
+	case "string":
+		return `""`
+	case "bool":
+		return "false"
+	case "int", "float":
+		return "0"
+	case "time":
+		return "time.Time{}"
+	}
+	return "*new(" + typ + ")"
+}
+
+func isPointer(typ string) bool { return strings.HasPrefix(typ, "*") }
+
+func isSlice(typ string) bool { return strings.HasPrefix(typ, "[]") }
+
+// elemType strips one pointer or slice from typ: "*[]string" becomes
+// "[]string".
+func elemType(typ string) string {
+	if isPointer(typ) {
+		return typ[1:]
+	}
+	if isSlice(typ) {
+		return typ[2:]
+	}
+	return typ
+}
+
+// indent prefixes every non-empty line of s with n tabs.
+func indent(n int, s string) string {
+	prefix := strings.Repeat("\t", n)
+	lines := strings.Split(s, "\n")
+	for i, l := range lines {
+		if l != "" {
+			lines[i] = prefix + l
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// join joins items with sep; it is written sep-first so that it pipes:
+// {{fieldNames .Fields | join ", "}}.
+func join(sep string, items []string) string {
+	return strings.Join(items, sep)
+}
+
+func quoteAll(items []string) []string {
+	out := make([]string, len(items))
+	for i, s := range items {
+		out[i] = strconv.Quote(s)
+	}
+	return out
+}
@@ -2,29 +2,430 @@ package generator
 
 import (
 	"bytes"
+	"errors"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
-	"regexp"
+	"runtime"
+	"slices"
+	"strconv"
 	"strings"
+	"sync"
 	"text/template"
 
+	"github.com/gotech-hub/dashgen/internal/naming"
 	"github.com/gotech-hub/dashgen/internal/parser"
 
 	"github.com/gotech-hub/dashgen/internal/templates"
 )
 
 type Config struct {
-	ModulePath  string
+	ModulePath  string // module path of ProjectRoot; empty detects it from go.mod/go.work
 	ProjectRoot string
 	Force       bool
 	DryRun      bool
+	Jobs        int       // number of entities rendered concurrently (<= 0 means GOMAXPROCS)
+	Log         io.Writer // progress messages; nil discards them
+	KeepGoing   bool      // generate every valid entity instead of stopping at the first error
+	Refresh     bool      // overwrite stale files that still carry the dashgen header, without Force
+
+	// Layer selection; see LayerModel and friends. Only renders just the
+	// given layers, Skip leaves layers out.
+	Only []string
+	Skip []string
+
+	// Version is written into the header of generated files; empty means
+	// "dev".
+	Version string
+
+	// Framework is the HTTP framework targeted by the api and routes
+	// layers, one of Frameworks(); empty means DefaultFramework.
+	Framework string
+
+	// Backend is the storage backend of the model layer, one of
+	// Backends(); empty means DefaultBackend.
+	Backend string
+
+	// Style is how the generated layers reach their dependencies, one of
+	// Styles(); empty means DefaultStyle.
+	Style string
+
+	// TemplatesDir holds *.tmpl files that override or extend the built-in
+	// templates; empty uses the built-in ones only.
+	TemplatesDir string
+
+	// API parameter constants; see DefaultConstantsFile and friends.
+	ConstantsFile    string // path relative to ProjectRoot
+	ConstantsPackage string // package name used when the file is created
+	ConstantName     string // naming scheme, "{Entity}" is replaced by the entity name
+	PruneConstants   bool   // remove constants whose entity no longer exists
+
+	// ProjectFiles renders the files built from all entities, such as the
+	// route registration file. Only enable it when the entities are the
+	// whole project.
+	ProjectFiles bool
 }
 
-func Generate(entities []parser.Entity, cfg Config) error {
-	for _, e := range entities {
-		if err := genOne(e, cfg); err != nil {
-			return err
+func (cfg Config) version() string {
+	if cfg.Version == "" {
+		return "dev"
+	}
+	return cfg.Version
+}
+
+func (cfg Config) log() io.Writer {
+	if cfg.Log == nil {
+		return io.Discard
+	}
+	return cfg.Log
+}
+
+// File is a rendered file held in memory.
+type File struct {
+	Path    string // relative to Config.ProjectRoot
+	Content []byte
+	Entity  string // entity the file was rendered for, empty for shared files
+	Layer   string // template that produced the file
+
+	// Merged files are written whenever they changed, even without
+	// Config.Force: either they already combine the file on disk with the
+	// generated content, like the constants file, or dashgen owns them as a
+	// whole, like the route registration file.
+	Merged bool
+}
+
+// projectFiles are rendered from all entities when Config.ProjectFiles is
+// set. Files marked partial are rendered on every run: the code generated
+// for the entities does not compile without them.
+var projectFiles = []struct {
+	layer   string
+	render  func([]parser.Entity, Config) (*File, error)
+	partial bool
+}{
+	{LayerRoutes, renderRoutes, false},
+	{LayerRegistry, renderRegistry, false},
+	{LayerAPI, renderHelpers, true},
+}
+
+// target is a single file rendered for an entity.
+type target struct {
+	layer string
+	path  string // relative to the project root
+	tpl   string // name of the parsed template
+}
+
+var (
+	parseOnce sync.Once
+	parsed    *template.Template
+	parseErr  error
+)
+
+// loadTemplates parses the built-in templates once and shares the result
+// between all renders.
+func loadTemplates() (*template.Template, error) {
+	parseOnce.Do(func() {
+		root := template.New("").Funcs(templateFuncs())
+		for name, src := range map[string]string{
+			"action":   templates.Action,
+			"api":      templates.API,
+			"client":   templates.Client,
+			"cursor":   templates.Cursor,
+			"filter":   templates.Filter,
+			"helpers":  templates.Helpers,
+			"memory":   templates.MemoryRepository,
+			"registry": templates.Registry,
+		} {
+			if _, err := root.New(name).Parse(src); err != nil {
+				parseErr = fmt.Errorf("parse template %s: %w", name, err)
+				return
+			}
+		}
+		for name, b := range backends {
+			for tpl, src := range map[string]string{"init": b.init, "repository": b.repository} {
+				if _, err := root.New(backendTemplate(tpl, name)).Parse(src); err != nil {
+					parseErr = fmt.Errorf("parse template %s: %w", backendTemplate(tpl, name), err)
+					return
+				}
+			}
+		}
+		for name, fw := range frameworks {
+			if _, err := root.New(routesTemplate(name)).Parse(fw.routes); err != nil {
+				parseErr = fmt.Errorf("parse template %s: %w", routesTemplate(name), err)
+				return
+			}
+		}
+		parsed = root
+	})
+	return parsed, parseErr
+}
+
+// templatesFor returns the templates of one run: the built-in ones with the
+// entity lookups bound to entities, "routes" set to the variant of
+// cfg.Framework, "init" and "repository" set to the variant of
+// cfg.Backend, and every *.tmpl file of cfg.TemplatesDir parsed on top. A
+// file named after a built-in template (e.g. api.tmpl) replaces it; other
+// files can hold shared definitions.
+func templatesFor(entities []parser.Entity, cfg Config) (*template.Template, error) {
+	base, err := loadTemplates()
+	if err != nil {
+		return nil, err
+	}
+	tpls, err := base.Clone()
+	if err != nil {
+		return nil, err
+	}
+	tpls.Funcs(entityFuncs(entities))
+	name, _, err := cfg.framework()
+	if err != nil {
+		return nil, err
+	}
+	if _, err := tpls.AddParseTree("routes", tpls.Lookup(routesTemplate(name)).Tree); err != nil {
+		return nil, err
+	}
+	backendName, _, err := cfg.backend()
+	if err != nil {
+		return nil, err
+	}
+	for _, tpl := range []string{"init", "repository"} {
+		if _, err := tpls.AddParseTree(tpl, tpls.Lookup(backendTemplate(tpl, backendName)).Tree); err != nil {
+			return nil, err
+		}
+	}
+
+	if cfg.TemplatesDir == "" {
+		return tpls, nil
+	}
+	paths, err := filepath.Glob(filepath.Join(cfg.TemplatesDir, "*.tmpl"))
+	if err != nil {
+		return nil, err
+	}
+	for _, p := range paths {
+		src, err := os.ReadFile(p)
+		if err != nil {
+			return nil, fmt.Errorf("read template: %w", err)
+		}
+		name := strings.TrimSuffix(filepath.Base(p), ".tmpl")
+		if _, err := tpls.New(name).Parse(string(src)); err != nil {
+			return nil, fmt.Errorf("parse template %s: %w", p, err)
+		}
+	}
+	return tpls, nil
+}
+
+// Render renders every output for entities in memory without touching the
+// disk, except for reading the constants file it merges into. Files are
+// returned in entity order followed by shared files.
+func Render(entities []parser.Entity, cfg Config) ([]File, error) {
+	rendered, errs, err := renderEntities(entities, cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	var files []File
+	for i, e := range entities {
+		if errs[i] != nil {
+			return nil, fmt.Errorf("generate %s: %w", e.Name, errs[i])
+		}
+		files = append(files, rendered[i]...)
+	}
+
+	for _, pf := range projectFiles {
+		if !(cfg.ProjectFiles || pf.partial) || !cfg.selects(pf.layer) {
+			continue
+		}
+		f, err := pf.render(entities, cfg)
+		if err != nil {
+			return nil, err
+		}
+		if f != nil {
+			files = append(files, *f)
+		}
+	}
+
+	if !cfg.selects(LayerConstants) {
+		return files, nil
+	}
+	c, _, err := renderConstants(entities, cfg)
+	if err != nil {
+		return nil, err
+	}
+	if c != nil {
+		files = append(files, *c)
+	}
+	return files, nil
+}
+
+// RenderProjectFiles renders the files built from all entities, such as
+// the route registration file, in memory. entities must be the whole
+// project; cfg.ProjectFiles is ignored.
+func RenderProjectFiles(entities []parser.Entity, cfg Config) ([]File, error) {
+	var files []File
+	for _, pf := range projectFiles {
+		if !cfg.selects(pf.layer) {
+			continue
+		}
+		f, err := pf.render(entities, cfg)
+		if err != nil {
+			return nil, err
+		}
+		if f != nil {
+			files = append(files, *f)
+		}
+	}
+	return files, nil
+}
+
+// renderEntities renders all entities with a bounded pool of workers and
+// returns the files and error of each entity at its index. The result does
+// not depend on how the workers are scheduled.
+func renderEntities(entities []parser.Entity, cfg Config) ([][]File, []error, error) {
+	tpls, err := templatesFor(entities, cfg)
+	if err != nil {
+		return nil, nil, err
+	}
+	custom := customLayers(tpls)
+	available := append(slices.Clone(builtinLayers), custom...)
+	if err := checkLayers(cfg.Only, available, "--only"); err != nil {
+		return nil, nil, err
+	}
+	if err := checkLayers(cfg.Skip, available, "--skip"); err != nil {
+		return nil, nil, err
+	}
+	im, err := newImports(cfg)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	jobs := cfg.Jobs
+	if jobs <= 0 {
+		jobs = runtime.GOMAXPROCS(0)
+	}
+
+	files := make([][]File, len(entities))
+	errs := make([]error, len(entities))
+
+	idx := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < min(jobs, len(entities)); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range idx {
+				files[i], errs[i] = genOne(entities[i], tpls, im, custom, cfg)
+			}
+		}()
+	}
+	for i := range entities {
+		idx <- i
+	}
+	close(idx)
+	wg.Wait()
+
+	return files, errs, nil
+}
+
+// EntityError is the failure to generate a single entity.
+type EntityError struct {
+	Entity string
+	Err    error
+}
+
+func (e *EntityError) Error() string { return fmt.Sprintf("generate %s: %v", e.Entity, e.Err) }
+
+func (e *EntityError) Unwrap() error { return e.Err }
+
+// Generate renders all entities and writes the results below
+// cfg.ProjectRoot. Everything is rendered in memory first so that a failing
+// entity leaves the project untouched. With cfg.KeepGoing the valid
+// entities are still written and the failures are returned joined as
+// *EntityError values. The returned report is never nil and describes every
+// target, also when an error is returned.
+func Generate(entities []parser.Entity, cfg Config) (*Report, error) {
+	log := cfg.log()
+	report := NewReport(cfg.DryRun)
+
+	rendered, renderErrs, err := renderEntities(entities, cfg)
+	if err != nil {
+		report.Diagnostics = append(report.Diagnostics, Diagnostic{Severity: "error", Message: err.Error()})
+		return report, err
+	}
+
+	var outs []output
+	var errs []error
+	var generated []parser.Entity
+	for i, e := range entities {
+		er := EntityReport{Name: e.Name, Package: e.PkgPath, DBName: e.DBName}
+		entityErr := renderErrs[i]
+
+		// An entity is written completely or not at all.
+		var entityOuts []output
+		if entityErr == nil {
+			for _, f := range rendered[i] {
+				action, o, err := writeIfNeeded(f, cfg)
+				tr := TargetReport{Layer: f.Layer, Path: f.Path, Action: action}
+				if err != nil {
+					tr.Error = err.Error()
+					entityErr = err
+				}
+				er.Targets = append(er.Targets, tr)
+				if o != nil {
+					entityOuts = append(entityOuts, *o)
+				}
+			}
+		}
+
+		if entityErr != nil {
+			err := &EntityError{Entity: e.Name, Err: entityErr}
+			er.Error = entityErr.Error()
+			for j := range er.Targets {
+				if t := &er.Targets[j]; t.Action == ActionCreated || t.Action == ActionOverwritten {
+					t.Action, t.Error = ActionError, "not written: "+er.Error
+				}
+			}
+			report.Entities = append(report.Entities, er)
+			report.Diagnostics = append(report.Diagnostics, Diagnostic{Severity: "error", Entity: e.Name, Message: err.Error()})
+			if !cfg.KeepGoing {
+				return report, err
+			}
+			errs = append(errs, err)
+			continue
+		}
+		report.Entities = append(report.Entities, er)
+		outs = append(outs, entityOuts...)
+		generated = append(generated, e)
+	}
+
+	// Entities that failed are left out of project-level files, the code
+	// those refer to may not exist.
+	for _, pf := range projectFiles {
+		if !(cfg.ProjectFiles || pf.partial) || !cfg.selects(pf.layer) {
+			continue
+		}
+		o, err := generateShared(cfg, report, func() (*File, error) { return pf.render(generated, cfg) })
+		if err != nil {
+			if !cfg.KeepGoing {
+				return report, err
+			}
+			errs = append(errs, err)
+		}
+		if o != nil {
+			outs = append(outs, *o)
+		}
+	}
+
+	// The constants file is shared by all entities, so it is updated once
+	// with the merged result.
+	if cfg.selects(LayerConstants) {
+		o, err := generateConstants(entities, cfg, report)
+		if err != nil {
+			if !cfg.KeepGoing {
+				return report, err
+			}
+			errs = append(errs, err)
+		}
+		if o != nil {
+			outs = append(outs, *o)
 		}
 	}
 
@@ -33,99 +434,283 @@ func Generate(entities []parser.Entity, cfg Config) error {
 	//     return err
 	// }
 
-	return nil
+	if cfg.DryRun {
+		return report, errors.Join(errs...)
+	}
+	if err := commit(outs, log); err != nil {
+		report.fail(err)
+		return report, errors.Join(append(errs, err)...)
+	}
+	return report, errors.Join(errs...)
+}
+
+// Write writes files that were rendered elsewhere below cfg.ProjectRoot
+// with the same rules as Generate: existing files are skipped unless
+// cfg.Force is set and everything is committed atomically. The files are
+// reported as shared targets.
+func Write(files []File, cfg Config) (*Report, error) {
+	report := NewReport(cfg.DryRun)
+	var outs []output
+	for _, f := range files {
+		action, o, err := writeIfNeeded(f, cfg)
+		tr := TargetReport{Layer: f.Layer, Path: f.Path, Action: action}
+		if err != nil {
+			tr.Error = err.Error()
+			report.Shared = append(report.Shared, tr)
+			report.fail(err)
+			return report, err
+		}
+		report.Shared = append(report.Shared, tr)
+		if o != nil {
+			outs = append(outs, *o)
+		}
+	}
+	if cfg.DryRun {
+		return report, nil
+	}
+	if err := commit(outs, cfg.log()); err != nil {
+		report.fail(err)
+		return report, err
+	}
+	return report, nil
+}
+
+// generateShared renders a project-level file and returns its output, or
+// nil when nothing is to be written. The shared target and any failure are
+// recorded in report.
+func generateShared(cfg Config, report *Report, render func() (*File, error)) (*output, error) {
+	f, err := render()
+	if err != nil {
+		report.Diagnostics = append(report.Diagnostics, Diagnostic{Severity: "error", Message: err.Error()})
+		return nil, err
+	}
+	if f == nil {
+		return nil, nil
+	}
+	action, o, err := writeIfNeeded(*f, cfg)
+	tr := TargetReport{Layer: f.Layer, Path: f.Path, Action: action}
+	if err != nil {
+		tr.Error = err.Error()
+		report.Diagnostics = append(report.Diagnostics, Diagnostic{Severity: "error", File: f.Path, Message: err.Error()})
+	}
+	report.Shared = append(report.Shared, tr)
+	return o, err
+}
+
+// generateConstants merges the constants of all entities into the constants
+// file and returns its output, or nil when nothing is to be written. The
+// shared target and any failure are recorded in report.
+func generateConstants(entities []parser.Entity, cfg Config, report *Report) (*output, error) {
+	log := cfg.log()
+	c, change, err := renderConstants(entities, cfg)
+	constantsPath := filepath.Join(cfg.ProjectRoot, cfg.constantsFile())
+	constants := TargetReport{Layer: LayerConstants, Path: cfg.constantsFile(), Action: ActionUnchanged}
+	if err != nil {
+		constants.Action, constants.Error = ActionError, err.Error()
+		report.Shared = append(report.Shared, constants)
+		report.Diagnostics = append(report.Diagnostics, Diagnostic{Severity: "error", File: constantsPath, Message: err.Error()})
+		return nil, err
+	}
+	if c != nil {
+		constants.Action = ActionOverwritten
+		if change.created {
+			constants.Action = ActionCreated
+		}
+	}
+	report.Shared = append(report.Shared, constants)
+
+	switch {
+	case c == nil && cfg.DryRun:
+		for _, name := range change.existing {
+			fmt.Fprintf(log, "constant %s already exists in: %s\n", name, constantsPath)
+		}
+	case c == nil:
+	case cfg.DryRun:
+		if change.created {
+			fmt.Fprintf(log, "would create constants file: %s\n", constantsPath)
+		}
+		for _, name := range change.added {
+			fmt.Fprintf(log, "would add constant %s to: %s\n", name, constantsPath)
+		}
+		for _, name := range change.removed {
+			fmt.Fprintf(log, "would remove constant %s from: %s\n", name, constantsPath)
+		}
+		if len(change.added) == 0 && len(change.removed) == 0 {
+			fmt.Fprintf(log, "would reformat constants file: %s\n", constantsPath)
+		}
+	default:
+		return &output{path: constantsPath, content: c.Content, message: change.message(constantsPath)}, nil
+	}
+	return nil, nil
 }
 
-func genOne(e parser.Entity, cfg Config) error {
+func genOne(e parser.Entity, tpls *template.Template, im *imports, custom []string, cfg Config) ([]File, error) {
+	if err := checkLayers(e.Layers, append(slices.Clone(builtinLayers), custom...), "@entity layers"); err != nil {
+		return nil, err
+	}
+
+	// Use the actual directory of the model package (e.g. "model/user")
+	modelDir := im.modelDir(e)
+
+	module, err := im.importPath(im.root)
+	if err != nil {
+		return nil, err
+	}
+	modelImport, err := im.importPath(filepath.Join(im.root, modelDir))
+	if err != nil {
+		return nil, err
+	}
+	constantsImport, err := im.importPath(filepath.Join(im.root, filepath.Dir(cfg.constantsFile())))
+	if err != nil {
+		return nil, err
+	}
+
+	paramConst, paramName := paramConstant(e, cfg)
+	fwName, fw, err := cfg.framework()
+	if err != nil {
+		return nil, err
+	}
+	commonImport, collectionImport := fw.common, fw.collection
+	if commonImport == "" {
+		commonImport = module + "/" + CommonDir
+	}
+	if collectionImport == "" {
+		collectionImport = module + "/" + CollectionDir
+	}
+	backendName, b, err := cfg.backend()
+	if err != nil {
+		return nil, err
+	}
+	style, err := cfg.style()
+	if err != nil {
+		return nil, err
+	}
 	ctx := map[string]any{
-		"Module":       cfg.ModulePath,
+		"Module":       module,
 		"PkgPath":      e.PkgPath,
+		"ModelImport":  modelImport,
+		"Package":      packageName(e),
 		"Entity":       e.Name,
-		"EntityLower":  strings.ToLower(e.Name[:1]) + e.Name[1:],
-		"EntitySnake":  toSnake(e.Name),
+		"EntityLower":  naming.Camel(e.Name),
+		"EntitySnake":  naming.Snake(e.Name),
+		"EntityKebab":  naming.Kebab(e.Name),
 		"EntityPlural": e.Plural,
 		"DBName":       e.DBName,
 		"Fields":       e.Fields,
 		"Indexes":      e.Indexes,
-	}
-
-	// Use the full PkgPath for model files (e.g., "model/user" -> "model/user/")
-	modelDir := e.PkgPath
 
-	targets := []struct{ path, tpl string }{
-		{path: filepath.Join(cfg.ProjectRoot, modelDir, "init.go"), tpl: templates.ModelInit},
-		{path: filepath.Join(cfg.ProjectRoot, modelDir, "repository.go"), tpl: templates.ModelRepository},
-		{path: filepath.Join(cfg.ProjectRoot, "internal/action", strings.ToLower(e.Name)+".go"), tpl: templates.Action},
-		{path: filepath.Join(cfg.ProjectRoot, "internal/api", strings.ToLower(e.Name)+".go"), tpl: templates.API},
-		{path: filepath.Join(cfg.ProjectRoot, "client", strings.ToLower(e.Name)+".go"), tpl: templates.Client},
+		"ConstantsPkg":    cfg.constantsPackage(),
+		"ConstantsImport": constantsImport,
+		"ParamConst":      paramConst,
+		"ParamName":       paramName,
+		"Routes":          routesByOp(entityRoutes(e, paramName)),
+
+		"Framework":      fwName,
+		"RequestType":    fw.request,
+		"ResponderType":  fw.responder,
+		"RequestContext": fw.context,
+		"CommonImport":   commonImport,
+
+		"Backend":          backendName,
+		"Dialect":          b.dialect,
+		"CollectionImport": collectionImport,
+
+		"Style": style,
+	}
+
+	targets := []target{
+		{layer: LayerModel, path: filepath.Join(modelDir, "init.go"), tpl: "init"},
+		{layer: LayerRepository, path: filepath.Join(modelDir, "repository.go"), tpl: "repository"},
+		{layer: LayerRepository, path: filepath.Join(modelDir, "filter.go"), tpl: "filter"},
+		{layer: LayerRepository, path: filepath.Join(modelDir, "cursor.go"), tpl: "cursor"},
+		{layer: LayerMemory, path: filepath.Join(modelDir, "memory.go"), tpl: "memory"},
+		{layer: LayerAction, path: filepath.Join("internal/action", strings.ToLower(e.Name)+".go"), tpl: "action"},
+		{layer: LayerAPI, path: filepath.Join("internal/api", strings.ToLower(e.Name)+".go"), tpl: "api"},
+		{layer: LayerClient, path: filepath.Join("client", strings.ToLower(e.Name)+".go"), tpl: "client"},
+	}
+	for _, layer := range custom {
+		var path bytes.Buffer
+		if err := tpls.ExecuteTemplate(&path, layer+pathSuffix, ctx); err != nil {
+			return nil, fmt.Errorf("render path of layer %s: %w", layer, err)
+		}
+		targets = append(targets, target{layer: layer, path: filepath.FromSlash(strings.TrimSpace(path.String())), tpl: layer})
 	}
 
 	// Skip generating router and init snippets for main.go - library no longer interacts with main.go
-	// routerSnippetPath := filepath.Join(cfg.ProjectRoot, "generated", "router_"+strings.ToLower(e.Name)+".go.snippet")
-	// initSnippetPath := filepath.Join(cfg.ProjectRoot, "generated", "init_"+strings.ToLower(e.Name)+".go.snippet")
+	// routerSnippetPath := filepath.Join("generated", "router_"+strings.ToLower(e.Name)+".go.snippet")
+	// initSnippetPath := filepath.Join("generated", "init_"+strings.ToLower(e.Name)+".go.snippet")
 	//
 	// targets = append(targets,
-	//     struct{ path, tpl string }{path: routerSnippetPath, tpl: templates.MainRouter},
-	//     struct{ path, tpl string }{path: initSnippetPath, tpl: templates.MainInit},
+	//     target{path: routerSnippetPath, tpl: "router"},
+	//     target{path: initSnippetPath, tpl: "maininit"},
 	// )
 
-	for _, t := range targets {
-		if err := writeIfNeeded(t.path, t.tpl, ctx, cfg); err != nil {
-			return err
-		}
+	hdr, err := header(e, im.root, cfg)
+	if err != nil {
+		return nil, err
 	}
 
-	// Generate/update constants file
-	if err := updateConstantsFile(e, cfg); err != nil {
-		return err
+	var files []File
+	for _, t := range targets {
+		if !cfg.selectsFor(e, t.layer) {
+			continue
+		}
+		var buf bytes.Buffer
+		if err := tpls.ExecuteTemplate(&buf, t.tpl, ctx); err != nil {
+			return nil, fmt.Errorf("render %s: %w", t.path, err)
+		}
+		files = append(files, File{Path: t.path, Content: withHeader(hdr, buf.Bytes()), Entity: e.Name, Layer: t.layer})
 	}
 
-	return nil
+	return files, nil
 }
 
-func writeIfNeeded(path, tpl string, ctx map[string]any, cfg Config) error {
-	// Check if file already exists (unless force is enabled)
-	if !cfg.Force {
-		if _, err := os.Stat(path); err == nil {
-			fmt.Printf("⚠️  File already exists, skipping: %s\n", path)
-			return nil
+// writeIfNeeded decides what happens to f and returns the output that
+// writes it below the project root, or nil when the file is left alone.
+func writeIfNeeded(f File, cfg Config) (Action, *output, error) {
+	log := cfg.log()
+	path := filepath.Join(cfg.ProjectRoot, f.Path)
+
+	action := ActionCreated
+	existing, err := os.ReadFile(path)
+	switch {
+	case err == nil:
+		// Leave identical files alone so their mtime does not trigger
+		// file watchers and reloaders.
+		if bytes.Equal(existing, f.Content) {
+			fmt.Fprintf(log, "File unchanged, skipping: %s\n", path)
+			return ActionUnchanged, nil, nil
+		}
+		// Check if file already exists (unless force is enabled). A file
+		// that still carries the dashgen header is generated code gone
+		// stale, which Refresh overwrites; without the header the user
+		// owns it.
+		if !cfg.Force && !f.Merged {
+			_, owned := ParseHeader(existing)
+			if !owned {
+				fmt.Fprintf(log, "⚠️  File already exists, skipping: %s\n", path)
+				return ActionSkippedExisting, nil, nil
+			}
+			if !cfg.Refresh {
+				fmt.Fprintf(log, "⚠️  Generated file is out of date, skipping (use --force): %s\n", path)
+				return ActionStale, nil, nil
+			}
 		}
+		action = ActionOverwritten
+	case !os.IsNotExist(err):
+		return ActionError, nil, err
 	}
 
 	if cfg.DryRun {
-		fmt.Println("would write:", path)
-		return nil
+		fmt.Fprintln(log, "would write:", path)
+		return action, nil, nil
 	}
 
-	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
-		return err
-	}
-
-	var buf bytes.Buffer
-	t := template.Must(template.New("tpl").Funcs(template.FuncMap{
-		"lower":              strings.ToLower,
-		"generateValidation": generateValidation,
-		"hasRequiredFields":  hasRequiredFields,
-		"generateIndexes":    generateIndexes,
-		"hasIndexes":         hasIndexes,
-	}).Parse(tpl))
-	if err := t.Execute(&buf, ctx); err != nil {
-		return err
-	}
-
-	fmt.Printf("✅ Generated: %s\n", path)
-	return os.WriteFile(path, buf.Bytes(), 0o644)
-}
-
-func toSnake(in string) string {
-	var out []rune
-	for i, r := range in {
-		if i > 0 && r >= 'A' && r <= 'Z' {
-			out = append(out, '_')
-		}
-		out = append(out, rune(strings.ToLower(string(r))[0]))
-	}
-	return string(out)
+	return action, &output{
+		path:    path,
+		content: f.Content,
+		message: fmt.Sprintf("✅ Generated: %s", path),
+	}, nil
 }
 
 // hasRequiredFields checks if any field has required validation
@@ -355,6 +940,13 @@ func generateCompoundIndex(index parser.Index, entityLower string) string {
 	if index.Name != "" {
 		options = append(options, fmt.Sprintf("Name: utils.GetPointer(\"%s\")", index.Name))
 	}
+	if len(index.Partial) > 0 {
+		var conds []string
+		for _, cond := range index.Partial {
+			conds = append(conds, fmt.Sprintf("{Key: \"%s\", Value: %s}", cond.Field, goLiteral(cond.Value)))
+		}
+		options = append(options, fmt.Sprintf("PartialFilterExpression: bson.D{%s}", strings.Join(conds, ", ")))
+	}
 
 	var optionsStr string
 	if len(options) > 0 {
@@ -384,104 +976,21 @@ func generateCompoundIndex(index parser.Index, entityLower string) string {
 	if index.Sparse {
 		comment += " (sparse)"
 	}
-
-	return fmt.Sprintf("\t// %s\n\terr = %sCollection.CreateIndex(%s%s)\n\tif err != nil {\n\t\treturn err\n\t}", comment, entityLower, indexDoc, optionsStr)
-}
-
-// updateConstantsFile adds or updates constants for the entity
-func updateConstantsFile(entity parser.Entity, cfg Config) error {
-	constantsPath := filepath.Join(cfg.ProjectRoot, "utils", "constants.go")
-	constantName := fmt.Sprintf("Param%sID", entity.Name)
-	constantValue := fmt.Sprintf("%s_id", strings.ToLower(entity.Name))
-
-	// Check if constants file exists
-	if _, err := os.Stat(constantsPath); os.IsNotExist(err) {
-		// Create new constants file
-		return createConstantsFile(constantsPath, entity, cfg)
-	}
-
-	// Read existing constants file
-	content, err := os.ReadFile(constantsPath)
-	if err != nil {
-		return fmt.Errorf("failed to read constants file: %v", err)
-	}
-
-	contentStr := string(content)
-
-	// Check if constant already exists using more precise matching
-	constantPattern := fmt.Sprintf(`\b%s\s*=`, constantName)
-	matched, _ := regexp.MatchString(constantPattern, contentStr)
-	if matched {
-		if cfg.DryRun {
-			fmt.Printf("constant %s already exists in: %s\n", constantName, constantsPath)
-		}
-		return nil // Constant already exists
-	}
-
-	if cfg.DryRun {
-		fmt.Printf("would add constant %s to: %s\n", constantName, constantsPath)
-		return nil
-	}
-
-	// Add constant to the end of the const block
-	newConstant := fmt.Sprintf("\t%s = \"%s\"\n", constantName, constantValue)
-
-	// Find the const block and insert before its closing parenthesis
-	if strings.Contains(contentStr, "const (") {
-		// Find the last closing parenthesis that belongs to a const block
-		constIndex := strings.Index(contentStr, "const (")
-		if constIndex != -1 {
-			// Find the matching closing parenthesis
-			afterConst := contentStr[constIndex:]
-			parenIndex := strings.LastIndex(afterConst, ")")
-			if parenIndex != -1 {
-				// Insert before the closing parenthesis
-				insertPos := constIndex + parenIndex
-				contentStr = contentStr[:insertPos] + newConstant + contentStr[insertPos:]
-			} else {
-				// No closing parenthesis found, append after const (
-				insertPos := constIndex + len("const (") + 1
-				contentStr = contentStr[:insertPos] + newConstant + contentStr[insertPos:]
-			}
-		}
-	} else {
-		// No const block found, create one or append to end
-		if strings.TrimSpace(contentStr) == "" || !strings.Contains(contentStr, "package") {
-			// Empty file or no package declaration
-			contentStr += fmt.Sprintf("const (\n%s)\n", newConstant)
-		} else {
-			// Append const block to end of file
-			contentStr = strings.TrimRight(contentStr, "\n") + "\n\n" + fmt.Sprintf("const (\n%s)\n", newConstant)
-		}
+	if len(index.Partial) > 0 {
+		comment += " (partial)"
 	}
 
-	fmt.Printf("✅ Updated constants: %s (added %s)\n", constantsPath, constantName)
-	return os.WriteFile(constantsPath, []byte(contentStr), 0o644)
+	return fmt.Sprintf("\t// %s\n\terr = %sCollection.CreateIndex(%s%s)\n\tif err != nil {\n\t\treturn err\n\t}", comment, entityLower, indexDoc, optionsStr)
 }
 
-// createConstantsFile creates a new constants file with the entity constant
-func createConstantsFile(constantsPath string, entity parser.Entity, cfg Config) error {
-	if cfg.DryRun {
-		fmt.Printf("would create constants file: %s\n", constantsPath)
-		return nil
+// goLiteral writes the value of a partial index condition as a Go literal:
+// true, false, null and numbers as such, other values as strings.
+func goLiteral(v string) string {
+	switch {
+	case v == "null":
+		return "nil"
+	case v == "true" || v == "false" || isNumber(v):
+		return v
 	}
-
-	// Create utils directory if it doesn't exist
-	if err := os.MkdirAll(filepath.Dir(constantsPath), 0o755); err != nil {
-		return fmt.Errorf("failed to create utils directory: %v", err)
-	}
-
-	constantName := fmt.Sprintf("Param%sID", entity.Name)
-	constantValue := fmt.Sprintf("%s_id", strings.ToLower(entity.Name))
-
-	content := fmt.Sprintf(`package constants
-
-// API parameter constants
-const (
-	%s = "%s"
-)
-`, constantName, constantValue)
-
-	fmt.Printf("✅ Created constants file: %s\n", constantsPath)
-	return os.WriteFile(constantsPath, []byte(content), 0o644)
+	return strconv.Quote(v)
 }
@@ -0,0 +1,46 @@
+package generator
+
+import (
+	"bytes"
+	"fmt"
+	"path/filepath"
+
+	"github.com/gotech-hub/dashgen/internal/parser"
+)
+
+// HelpersFile is the generated file holding the declarations shared by the
+// handlers of all entities, relative to the project root.
+const HelpersFile = "internal/api/zz_helpers.go"
+
+// renderHelpers renders the helpers file when the api layer of an entity is
+// selected, or returns nil. Its content only depends on the framework, so
+// partial runs render the same file as full ones.
+func renderHelpers(entities []parser.Entity, cfg Config) (*File, error) {
+	selected := false
+	for _, e := range entities {
+		if cfg.selectsFor(e, LayerAPI) {
+			selected = true
+			break
+		}
+	}
+	if !selected {
+		return nil, nil
+	}
+	name, _, err := cfg.framework()
+	if err != nil {
+		return nil, err
+	}
+	tpls, err := templatesFor(entities, cfg)
+	if err != nil {
+		return nil, err
+	}
+	hdr, err := projectHeader(nil, cfg)
+	if err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	if err := tpls.ExecuteTemplate(&buf, "helpers", map[string]any{"Framework": name}); err != nil {
+		return nil, fmt.Errorf("render %s: %w", HelpersFile, err)
+	}
+	return &File{Path: filepath.FromSlash(HelpersFile), Content: withHeader(hdr, buf.Bytes()), Layer: LayerAPI, Merged: true}, nil
+}
@@ -0,0 +1,76 @@
+package generator
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/gotech-hub/dashgen/internal/gomod"
+	"github.com/gotech-hub/dashgen/internal/parser"
+)
+
+// imports maps project directories to Go import paths. With an explicit
+// Config.ModulePath the project root is that module; otherwise the modules
+// are read from the go.mod or go.work files above the root.
+type imports struct {
+	root   string // absolute project root
+	module string // explicit module path of the root, if any
+	mods   *gomod.Resolver
+}
+
+func newImports(cfg Config) (*imports, error) {
+	root, err := filepath.Abs(cfg.ProjectRoot)
+	if err != nil {
+		return nil, err
+	}
+	im := &imports{root: root, module: cfg.ModulePath}
+
+	mods, err := gomod.NewResolver(root)
+	if err != nil && cfg.ModulePath == "" {
+		return nil, fmt.Errorf("cannot detect the module path (pass --module): %w", err)
+	}
+	im.mods = mods
+	return im, nil
+}
+
+// importPath returns the import path of the package in dir.
+func (im *imports) importPath(dir string) (string, error) {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return "", err
+	}
+	if im.module != "" {
+		rel, err := filepath.Rel(im.root, dir)
+		if err == nil && !strings.HasPrefix(rel, "..") {
+			if rel == "." {
+				return im.module, nil
+			}
+			return im.module + "/" + filepath.ToSlash(rel), nil
+		}
+	}
+	if im.mods == nil {
+		return "", fmt.Errorf("%s is outside the project root %s", dir, im.root)
+	}
+	return im.mods.ImportPath(dir)
+}
+
+// modelDir returns the directory of the entity's model package relative to
+// the project root.
+func (im *imports) modelDir(e parser.Entity) string {
+	if e.Dir == "" {
+		return e.PkgPath
+	}
+	rel, err := filepath.Rel(im.root, e.Dir)
+	if err != nil {
+		return e.PkgPath
+	}
+	return rel
+}
+
+// packageName returns the package name of the entity's model package.
+func packageName(e parser.Entity) string {
+	if e.Package != "" {
+		return e.Package
+	}
+	return strings.ToLower(e.Name)
+}
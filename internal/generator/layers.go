@@ -0,0 +1,75 @@
+package generator
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"text/template"
+
+	"github.com/gotech-hub/dashgen/internal/parser"
+)
+
+// Built-in layers. Every layer but routes, registry and constants renders
+// one file per entity; repository also renders the entity's filter.go
+// and cursor.go.
+const (
+	LayerModel      = "model"
+	LayerRepository = "repository"
+	LayerMemory     = "memory"
+	LayerAction     = "action"
+	LayerAPI        = "api"
+	LayerClient     = "client"
+	LayerRoutes     = "routes"
+	LayerRegistry   = "registry"
+	LayerConstants  = "constants"
+)
+
+// builtinLayers lists the built-in layers in the order they are rendered.
+var builtinLayers = []string{LayerModel, LayerRepository, LayerMemory, LayerAction, LayerAPI, LayerClient, LayerRoutes, LayerRegistry, LayerConstants}
+
+// pathSuffix marks the template that renders the output path of a custom
+// layer: a user template "graphql" becomes a layer as soon as a template
+// "graphql.path" is defined next to it.
+const pathSuffix = ".path"
+
+// customLayers returns the custom layers defined by user templates, sorted
+// by name.
+func customLayers(tpls *template.Template) []string {
+	var layers []string
+	for _, t := range tpls.Templates() {
+		name, ok := strings.CutSuffix(t.Name(), pathSuffix)
+		if !ok || tpls.Lookup(name) == nil || slices.Contains(builtinLayers, name) {
+			continue
+		}
+		layers = append(layers, name)
+	}
+	sort.Strings(layers)
+	return layers
+}
+
+// selects reports whether --only and --skip leave layer enabled.
+func (cfg Config) selects(layer string) bool {
+	if len(cfg.Only) > 0 && !slices.Contains(cfg.Only, layer) {
+		return false
+	}
+	return !slices.Contains(cfg.Skip, layer)
+}
+
+// selectsFor is like selects but also honours the layers option of e.
+func (cfg Config) selectsFor(e parser.Entity, layer string) bool {
+	if len(e.Layers) > 0 && !slices.Contains(e.Layers, layer) {
+		return false
+	}
+	return cfg.selects(layer)
+}
+
+// checkLayers fails on the first name that is not an available layer.
+func checkLayers(names, available []string, what string) error {
+	for _, n := range names {
+		if !slices.Contains(available, n) {
+			return fmt.Errorf("unknown layer %q in %s (available: %s)", n, what, strings.Join(available, ", "))
+		}
+	}
+	return nil
+}
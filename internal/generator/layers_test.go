@@ -0,0 +1,66 @@
+package generator
+
+import (
+	"io"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/gotech-hub/dashgen/internal/parser"
+)
+
+func TestLayerSelection(t *testing.T) {
+	widget := parser.Entity{
+		PkgPath: "model/widget",
+		Package: "widget",
+		Name:    "Widget",
+		Plural:  "Widgets",
+		DBName:  "widgets",
+		Fields:  widgetFields,
+	}
+	tests := []struct {
+		name       string
+		layers     []string // of the entity
+		only, skip []string
+		want       []string
+		err        string
+	}{
+		{name: "entity layers", layers: []string{LayerModel, LayerAPI}, want: []string{LayerModel, LayerAPI}},
+		{name: "only", only: []string{LayerClient}, want: []string{LayerClient}},
+		{name: "skip", skip: []string{LayerModel, LayerRepository, LayerMemory, LayerAction, LayerAPI, LayerConstants}, want: []string{LayerClient}},
+		{name: "entity layers and only", layers: []string{LayerModel, LayerAPI}, only: []string{LayerAPI, LayerClient}, want: []string{LayerAPI}},
+		{name: "unknown entity layer", layers: []string{"graphql"}, err: `unknown layer "graphql" in @entity layers`},
+		{name: "unknown only layer", only: []string{"graphql"}, err: `unknown layer "graphql" in --only`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := widget
+			e.Layers = tt.layers
+			files, err := Render([]parser.Entity{e}, Config{
+				ModulePath:  "example.com/app",
+				ProjectRoot: t.TempDir(),
+				Log:         io.Discard,
+				Only:        tt.only,
+				Skip:        tt.skip,
+			})
+			if tt.err != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.err) {
+					t.Fatalf("Render() error = %v, want %q", err, tt.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got []string
+			for _, f := range files {
+				if !slices.Contains(got, f.Layer) {
+					got = append(got, f.Layer)
+				}
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("rendered layers %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,116 @@
+package generator
+
+import (
+	"strings"
+
+	"github.com/gotech-hub/dashgen/internal/parser"
+)
+
+// memoryField is a stored field as the in-memory repository sees it: the
+// names filters and sorts may use for it, and whether MongoDB would leave
+// its zero value out of the document.
+type memoryField struct {
+	Field     string // Go field name
+	BSONName  string // field name in MongoDB, the default name in filters
+	JSONName  string
+	OmitEmpty bool // bson omitempty
+	Primary   bool // the field tagged bson:"_id"
+}
+
+// memoryFields returns the stored fields in declaration order. Like the
+// MongoDB driver, fields without a bson name use their lowercased name,
+// and fields tagged bson:"-" are not stored.
+func memoryFields(fields []parser.Field) []memoryField {
+	var out []memoryField
+	for _, f := range fields {
+		name, opts, _ := strings.Cut(f.BSONTag, ",")
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = strings.ToLower(f.Name)
+		}
+		jsonName, _, _ := strings.Cut(f.JSONTag, ",")
+		if jsonName == "" || jsonName == "-" {
+			jsonName = f.Name
+		}
+		out = append(out, memoryField{
+			Field:     f.Name,
+			BSONName:  name,
+			JSONName:  jsonName,
+			OmitEmpty: strings.Contains(","+opts+",", ",omitempty,"),
+			Primary:   name == "_id",
+		})
+	}
+	return out
+}
+
+// memoryIndex is a unique index the in-memory repository enforces.
+type memoryIndex struct {
+	Fields  []string          // bson names
+	Sparse  bool              // skip documents missing all of Fields
+	Partial []memoryCondition // only matching documents are indexed
+}
+
+// memoryCondition is a partial index condition with its value as a Go
+// literal.
+type memoryCondition struct {
+	Field string // bson name
+	Value string
+}
+
+// memoryIndexes returns the unique indexes of an entity: one on the key
+// field, then the unique field and compound indexes, without duplicates.
+// Indexes on names that are not stored fields, such as nested paths, are
+// left out.
+func memoryIndexes(fields []parser.Field, indexes []parser.Index, key string) []memoryIndex {
+	stored := map[string]string{}
+	for _, f := range memoryFields(fields) {
+		stored[f.Field] = f.BSONName
+	}
+	var out []memoryIndex
+	plain := map[string]bool{} // fields of the indexes covering every document
+	add := func(idx memoryIndex) {
+		id := strings.Join(idx.Fields, ",")
+		if plain[id] {
+			return
+		}
+		plain[id] = !idx.Sparse && len(idx.Partial) == 0
+		out = append(out, idx)
+	}
+
+	if name, ok := stored[key]; ok {
+		add(memoryIndex{Fields: []string{name}})
+	}
+	for _, f := range fields {
+		if name, ok := stored[f.Name]; ok && f.Index == "unique" {
+			add(memoryIndex{Fields: []string{name}})
+		}
+	}
+
+	names := map[string]bool{}
+	for _, name := range stored {
+		names[name] = true
+	}
+next:
+	for _, idx := range indexes {
+		if !idx.Unique {
+			continue
+		}
+		m := memoryIndex{Sparse: idx.Sparse}
+		for _, f := range idx.Fields {
+			if !names[f.Name] {
+				continue next
+			}
+			m.Fields = append(m.Fields, f.Name)
+		}
+		for _, cond := range idx.Partial {
+			if !names[cond.Field] {
+				continue next
+			}
+			m.Partial = append(m.Partial, memoryCondition{Field: cond.Field, Value: goLiteral(cond.Value)})
+		}
+		add(m)
+	}
+	return out
+}
@@ -0,0 +1,173 @@
+package generator
+
+import (
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/gotech-hub/dashgen/internal/parser"
+)
+
+// postgresDSNEnv names the environment variable holding the DSN of the
+// PostgreSQL database TestPostgresRoundTrip runs against.
+const postgresDSNEnv = "DASHGEN_POSTGRES_DSN"
+
+const roundTripData = `package widget
+
+import "time"
+
+// @entity db:dashgen_roundtrip_widgets
+// @index status:1,price:-1 partial:status=active cursor
+type Widget struct {
+	ID        string    ` + "`json:\"id\" bson:\"_id\"`" + `
+	WidgetID  string    ` + "`json:\"widget_id\" bson:\"widget_id\"`" + `
+	SKU       string    ` + "`json:\"sku\" bson:\"sku\" index:\"unique\"`" + `
+	Status    string    ` + "`json:\"status\" bson:\"status\"`" + `
+	Price     float64   ` + "`json:\"price\" bson:\"price\"`" + `
+	Tags      []string  ` + "`json:\"tags\" bson:\"tags\"`" + `
+	CreatedAt time.Time ` + "`json:\"created_at\" bson:\"created_at\"`" + `
+}
+`
+
+const roundTripTest = `package widget
+
+import (
+	"context"
+	"database/sql"
+	"os"
+	"testing"
+
+	_ "github.com/jackc/pgx/v5/stdlib"
+)
+
+func TestRoundTrip(t *testing.T) {
+	ctx := context.Background()
+	db, err := sql.Open("pgx", os.Getenv("` + postgresDSNEnv + `"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	if _, err := db.ExecContext(ctx, "DROP TABLE IF EXISTS dashgen_roundtrip_widgets"); err != nil {
+		t.Fatal(err)
+	}
+	defer db.ExecContext(ctx, "DROP TABLE dashgen_roundtrip_widgets")
+
+	if err := Init(ctx, db); err != nil {
+		t.Fatal(err)
+	}
+	// Init is idempotent.
+	if err := Init(ctx, db); err != nil {
+		t.Fatal(err)
+	}
+	repo := GetRepository()
+
+	w, err := repo.Create(ctx, &Widget{ID: "1", WidgetID: "w1", SKU: "B-1", Status: "active", Price: 2.5, Tags: []string{"a", "b"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if w.SKU != "B-1" || len(w.Tags) != 2 {
+		t.Fatalf("Create() = %+v", w)
+	}
+	if _, err := repo.Create(ctx, &Widget{ID: "2", WidgetID: "w2", SKU: "B-1"}); err == nil {
+		t.Fatal("Create() with a duplicate SKU succeeded")
+	}
+
+	got, err := repo.GetByWidgetID(ctx, "w1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Price != 2.5 || got.Tags[1] != "b" {
+		t.Fatalf("GetByWidgetID() = %+v", got)
+	}
+
+	updated, err := repo.UpdateByWidgetID(ctx, "w1", &Widget{Price: 3})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if updated.Price != 3 || updated.SKU != "B-1" {
+		t.Fatalf("UpdateByWidgetID() = %+v", updated)
+	}
+
+	list, err := repo.List(ctx, NewWidgetFilter().StatusEq("active"), 0, 10, map[string]int{"price": -1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(list) != 1 || list[0].WidgetID != "w1" {
+		t.Fatalf("List() = %+v", list)
+	}
+	page, err := repo.ListPage(ctx, nil, "", 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(page.Items) != 1 {
+		t.Fatalf("ListPage() = %+v", page)
+	}
+
+	if err := repo.DeleteByWidgetID(ctx, "w1"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := repo.GetByWidgetID(ctx, "w1"); err == nil {
+		t.Fatal("GetByWidgetID() found a deleted widget")
+	}
+	if n, err := repo.Count(ctx, nil); err != nil || n != 0 {
+		t.Fatalf("Count() = %d, %v after delete", n, err)
+	}
+	// The unique indexes only cover live rows.
+	if _, err := repo.Create(ctx, &Widget{ID: "3", WidgetID: "w3", SKU: "B-1"}); err != nil {
+		t.Fatalf("Create() with the SKU of a deleted widget: %v", err)
+	}
+}
+`
+
+// TestPostgresRoundTrip generates the postgres model layer of an entity
+// into a new module and runs a create, read, update, list and delete round
+// trip against the database named by DASHGEN_POSTGRES_DSN.
+func TestPostgresRoundTrip(t *testing.T) {
+	dsn := os.Getenv(postgresDSNEnv)
+	if dsn == "" {
+		t.Skip(postgresDSNEnv + " not set")
+	}
+	if _, err := exec.LookPath("go"); err != nil {
+		t.Skip("go tool not found")
+	}
+
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "go.mod"), "module example.com/roundtrip\n\ngo 1.24\n")
+	writeFile(t, filepath.Join(dir, "model/widget/data.go"), roundTripData)
+	writeFile(t, filepath.Join(dir, "model/widget/roundtrip_test.go"), roundTripTest)
+
+	entities, err := parser.ParseDataGo(filepath.Join(dir, "model/widget/data.go"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg := Config{
+		ProjectRoot: dir,
+		Log:         io.Discard,
+		Backend:     BackendPostgres,
+		Only:        []string{LayerModel, LayerRepository},
+	}
+	if _, err := Generate(entities, cfg); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, args := range [][]string{{"mod", "tidy"}, {"test", "-count=1", "./model/..."}} {
+		cmd := exec.Command("go", args...)
+		cmd.Dir = dir
+		cmd.Env = append(os.Environ(), "GOFLAGS=-mod=mod", "GOWORK=off")
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("go %v: %v\n%s", args, err, out)
+		}
+	}
+}
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,112 @@
+package generator
+
+import (
+	"bytes"
+	"fmt"
+	"path/filepath"
+	"sort"
+
+	"github.com/gotech-hub/dashgen/internal/parser"
+)
+
+// RegistryFile is the generated model registry, relative to the project
+// root.
+const RegistryFile = "model/registry.go"
+
+// registryPackage is a model package whose Init the registry calls.
+type registryPackage struct {
+	Alias  string // import name, unique within the registry
+	Import string
+	Dir    string // display path, e.g. "model/user"
+}
+
+// registryEntity is an entry of the registry's entity list.
+type registryEntity struct {
+	Name       string
+	Package    string
+	Collection string
+	Alias      string // import name of the package
+	importPath string
+}
+
+// renderRegistry renders the model registry for the entities whose model
+// and registry layers are selected, or returns nil when there are none.
+func renderRegistry(entities []parser.Entity, cfg Config) (*File, error) {
+	im, err := newImports(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	var included []parser.Entity
+	var list []registryEntity
+	pkgs := map[string]*registryPackage{}
+	for _, e := range entities {
+		if !cfg.selectsFor(e, LayerRegistry) || !cfg.selectsFor(e, LayerModel) {
+			continue
+		}
+		dir := im.modelDir(e)
+		imp, err := im.importPath(filepath.Join(im.root, dir))
+		if err != nil {
+			return nil, err
+		}
+		if pkgs[imp] == nil {
+			pkgs[imp] = &registryPackage{Alias: packageName(e), Import: imp, Dir: filepath.ToSlash(dir)}
+		}
+		included = append(included, e)
+		list = append(list, registryEntity{Name: e.Name, Package: filepath.ToSlash(dir), Collection: e.DBName, importPath: imp})
+	}
+	if len(included) == 0 {
+		return nil, nil
+	}
+
+	// Packages are initialised in import path order; equal package names
+	// get numbered aliases.
+	var sorted []registryPackage
+	for _, p := range pkgs {
+		sorted = append(sorted, *p)
+	}
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Import < sorted[j].Import })
+	used := map[string]bool{}
+	aliases := map[string]string{}
+	for i := range sorted {
+		alias := sorted[i].Alias
+		for n := 2; used[alias]; n++ {
+			alias = fmt.Sprintf("%s%d", sorted[i].Alias, n)
+		}
+		used[alias] = true
+		sorted[i].Alias = alias
+		aliases[sorted[i].Import] = alias
+	}
+	for i := range list {
+		list[i].Alias = aliases[list[i].importPath]
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Name < list[j].Name })
+
+	_, b, err := cfg.backend()
+	if err != nil {
+		return nil, err
+	}
+	style, err := cfg.style()
+	if err != nil {
+		return nil, err
+	}
+	tpls, err := templatesFor(entities, cfg)
+	if err != nil {
+		return nil, err
+	}
+	hdr, err := projectHeader(included, cfg)
+	if err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	if err := tpls.ExecuteTemplate(&buf, "registry", map[string]any{
+		"Packages": sorted,
+		"Entities": list,
+		"DBType":   b.dbType,
+		"DBImport": b.dbImport,
+		"Style":    style,
+	}); err != nil {
+		return nil, fmt.Errorf("render %s: %w", RegistryFile, err)
+	}
+	return &File{Path: filepath.FromSlash(RegistryFile), Content: withHeader(hdr, buf.Bytes()), Layer: LayerRegistry, Merged: true}, nil
+}
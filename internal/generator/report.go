@@ -0,0 +1,108 @@
+package generator
+
+// Action is what a run did, or would do in a dry run, with a target file.
+type Action string
+
+const (
+	ActionCreated         Action = "created"
+	ActionOverwritten     Action = "overwritten"
+	ActionSkippedExisting Action = "skipped-existing"
+	ActionStale           Action = "stale" // a dashgen-owned file that is out of date, left alone without Force
+	ActionUnchanged       Action = "unchanged"
+	ActionError           Action = "error"
+)
+
+// Report describes the outcome of a generation run.
+type Report struct {
+	DryRun      bool           `json:"dry_run"`
+	Entities    []EntityReport `json:"entities"`
+	Shared      []TargetReport `json:"shared"` // files not owned by a single entity
+	Diagnostics []Diagnostic   `json:"diagnostics"`
+}
+
+// NewReport returns an empty report whose lists encode as [] rather than null.
+func NewReport(dryRun bool) *Report {
+	return &Report{
+		DryRun:      dryRun,
+		Entities:    []EntityReport{},
+		Shared:      []TargetReport{},
+		Diagnostics: []Diagnostic{},
+	}
+}
+
+// EntityReport lists the targets rendered for one entity.
+type EntityReport struct {
+	Name    string         `json:"name"`
+	Package string         `json:"package"`
+	DBName  string         `json:"db"`
+	Targets []TargetReport `json:"targets"`
+	Error   string         `json:"error,omitempty"`
+}
+
+// TargetReport is the outcome for a single file.
+type TargetReport struct {
+	Layer  string `json:"layer"`
+	Path   string `json:"path"`
+	Action Action `json:"action"`
+	Error  string `json:"error,omitempty"`
+}
+
+// Diagnostic is a problem found while parsing or generating.
+type Diagnostic struct {
+	Severity string `json:"severity"` // "error" or "warning"
+	File     string `json:"file,omitempty"`
+	Entity   string `json:"entity,omitempty"`
+	Message  string `json:"message"`
+}
+
+// targets returns every target of the report, entity targets first.
+func (r *Report) targets() []*TargetReport {
+	var all []*TargetReport
+	for i := range r.Entities {
+		for j := range r.Entities[i].Targets {
+			all = append(all, &r.Entities[i].Targets[j])
+		}
+	}
+	for i := range r.Shared {
+		all = append(all, &r.Shared[i])
+	}
+	return all
+}
+
+// Count returns how many targets ended with action a.
+func (r *Report) Count(a Action) int {
+	n := 0
+	for _, t := range r.targets() {
+		if t.Action == a {
+			n++
+		}
+	}
+	return n
+}
+
+// HasErrors reports whether any target failed or an error was diagnosed.
+func (r *Report) HasErrors() bool {
+	for _, d := range r.Diagnostics {
+		if d.Severity == "error" {
+			return true
+		}
+	}
+	return r.Count(ActionError) > 0
+}
+
+// Drift reports whether the files on disk differ from what would be
+// generated: some target is (or would be) created or overwritten, or a
+// file dashgen owns is stale.
+func (r *Report) Drift() bool {
+	return r.Count(ActionCreated)+r.Count(ActionOverwritten)+r.Count(ActionStale) > 0
+}
+
+// fail marks every target that was about to be written as failed with err.
+func (r *Report) fail(err error) {
+	for _, t := range r.targets() {
+		if t.Action == ActionCreated || t.Action == ActionOverwritten {
+			t.Action = ActionError
+			t.Error = err.Error()
+		}
+	}
+}
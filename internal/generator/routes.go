@@ -0,0 +1,112 @@
+package generator
+
+import (
+	"bytes"
+	"fmt"
+	"path/filepath"
+
+	"github.com/gotech-hub/dashgen/internal/naming"
+	"github.com/gotech-hub/dashgen/internal/parser"
+)
+
+// Route is an endpoint of the generated API. The client templates and the
+// route registration file are both rendered from it, so the two cannot
+// disagree.
+type Route struct {
+	Op      string // Create, Get, List, Page, Update or Delete
+	Method  string // HTTP method
+	Path    string
+	Handler string // handler function in internal/api
+	Param   string // query parameter carrying the entity ID, if any
+}
+
+// RoutesFile is the generated route registration file, relative to the
+// project root.
+const RoutesFile = "internal/api/zz_routes.go"
+
+// entityRoutes returns the endpoints of an entity in registration order.
+func entityRoutes(e parser.Entity, param string) []Route {
+	base := "/v1/" + naming.Camel(e.Name)
+	plural := "/v1/" + naming.Camel(e.Plural)
+	return []Route{
+		{Op: "Create", Method: "POST", Path: base, Handler: "Create" + e.Name},
+		{Op: "Get", Method: "GET", Path: base, Handler: "Get" + e.Name + "By" + e.Name + "ID", Param: param},
+		{Op: "List", Method: "QUERY", Path: plural, Handler: "Query" + e.Plural},
+		{Op: "Page", Method: "QUERY", Path: plural + "/page", Handler: "Page" + e.Plural},
+		{Op: "Update", Method: "PUT", Path: base, Handler: "Update" + e.Name, Param: param},
+		{Op: "Delete", Method: "DELETE", Path: base, Handler: "Delete" + e.Name, Param: param},
+	}
+}
+
+// routesByOp indexes routes for templates: {{.Routes.Get.Path}}.
+func routesByOp(routes []Route) map[string]Route {
+	m := make(map[string]Route, len(routes))
+	for _, r := range routes {
+		m[r.Op] = r
+	}
+	return m
+}
+
+// renderRoutes renders the route registration file for the entities whose
+// routes layer is selected, or returns nil when there are none. With the
+// di style, handlers are methods of the entity's handler in Handlers, and
+// the file also wires them to the model registry's Repositories.
+func renderRoutes(entities []parser.Entity, cfg Config) (*File, error) {
+	style, err := cfg.style()
+	if err != nil {
+		return nil, err
+	}
+	var routes []Route
+	var names []string
+	var included []parser.Entity
+	for _, e := range entities {
+		if !cfg.selectsFor(e, LayerRoutes) || !cfg.selectsFor(e, LayerAPI) {
+			continue
+		}
+		_, param := paramConstant(e, cfg)
+		for _, r := range entityRoutes(e, param) {
+			if style == StyleDI {
+				r.Handler = "h." + e.Name + "." + r.Handler
+			}
+			routes = append(routes, r)
+		}
+		names = append(names, e.Name)
+		included = append(included, e)
+	}
+	if len(routes) == 0 {
+		return nil, nil
+	}
+
+	im, err := newImports(cfg)
+	if err != nil {
+		return nil, err
+	}
+	modelImport, err := im.importPath(filepath.Join(im.root, filepath.Dir(RegistryFile)))
+	if err != nil {
+		return nil, err
+	}
+	actionImport, err := im.importPath(filepath.Join(im.root, "internal/action"))
+	if err != nil {
+		return nil, err
+	}
+
+	tpls, err := templatesFor(entities, cfg)
+	if err != nil {
+		return nil, err
+	}
+	hdr, err := projectHeader(included, cfg)
+	if err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	if err := tpls.ExecuteTemplate(&buf, "routes", map[string]any{
+		"Routes":       routes,
+		"Style":        style,
+		"Entities":     names,
+		"ModelImport":  modelImport,
+		"ActionImport": actionImport,
+	}); err != nil {
+		return nil, fmt.Errorf("render %s: %w", RoutesFile, err)
+	}
+	return &File{Path: filepath.FromSlash(RoutesFile), Content: withHeader(hdr, buf.Bytes()), Layer: LayerRoutes, Merged: true}, nil
+}
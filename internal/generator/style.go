@@ -0,0 +1,38 @@
+package generator
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// Generation styles: how the generated layers reach their dependencies.
+const (
+	// StyleGlobal keeps the repository of a model package in a package
+	// variable set by Init; actions fetch it with GetRepository.
+	StyleGlobal = "global"
+	// StyleDI constructs repositories with NewRepository; services and
+	// handlers are structs holding their dependencies, wired by the
+	// generated NewRepositories and NewHandlers.
+	StyleDI = "di"
+)
+
+// DefaultStyle is used when Config.Style is empty.
+const DefaultStyle = StyleGlobal
+
+// Styles returns the supported generation styles, sorted.
+func Styles() []string {
+	return []string{StyleDI, StyleGlobal}
+}
+
+// style returns the configured generation style.
+func (cfg Config) style() (string, error) {
+	name := cfg.Style
+	if name == "" {
+		name = DefaultStyle
+	}
+	if !slices.Contains(Styles(), name) {
+		return "", fmt.Errorf("unknown style %q (available: %s)", name, strings.Join(Styles(), ", "))
+	}
+	return name, nil
+}
@@ -0,0 +1,176 @@
+package generator
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+// output is a fully rendered file waiting to be committed to disk.
+type output struct {
+	path    string
+	content []byte
+	// message is printed once the file has been committed
+	message string
+}
+
+// txn commits a set of rendered outputs atomically: every file is first
+// staged next to its target and only then renamed into place. If anything
+// fails, staged files are discarded, already replaced files are restored
+// and directories created by the transaction are removed again.
+type txn struct {
+	staged    []stagedFile
+	committed []stagedFile
+	dirs      []string
+	log       io.Writer
+}
+
+type stagedFile struct {
+	out     output
+	tmp     string
+	existed bool
+	backup  []byte
+	mode    os.FileMode
+}
+
+// commit writes all outputs or none of them and logs their messages once
+// everything is in place.
+func commit(outs []output, log io.Writer) error {
+	t := &txn{log: log}
+	if err := t.stage(outs); err != nil {
+		t.rollback()
+		return err
+	}
+	if err := t.apply(); err != nil {
+		if rerr := t.rollback(); rerr != nil {
+			return errors.Join(err, fmt.Errorf("rollback: %w", rerr))
+		}
+		return err
+	}
+	return nil
+}
+
+func (t *txn) stage(outs []output) error {
+	for _, o := range outs {
+		dir := filepath.Dir(o.path)
+		if err := t.mkdirAll(dir); err != nil {
+			return err
+		}
+
+		sf := stagedFile{out: o, mode: 0o644}
+		if info, err := os.Stat(o.path); err == nil {
+			prev, rerr := os.ReadFile(o.path)
+			if rerr != nil {
+				return fmt.Errorf("backup %s: %w", o.path, rerr)
+			}
+			sf.existed = true
+			sf.backup = prev
+			sf.mode = info.Mode().Perm()
+		}
+
+		tmp, err := writeTemp(dir, o.content, sf.mode)
+		if err != nil {
+			return fmt.Errorf("stage %s: %w", o.path, err)
+		}
+		sf.tmp = tmp
+		t.staged = append(t.staged, sf)
+	}
+	return nil
+}
+
+func (t *txn) apply() error {
+	for _, sf := range t.staged {
+		if err := os.Rename(sf.tmp, sf.out.path); err != nil {
+			return fmt.Errorf("write %s: %w", sf.out.path, err)
+		}
+		t.committed = append(t.committed, sf)
+	}
+	for _, sf := range t.committed {
+		if sf.out.message != "" {
+			fmt.Fprintln(t.log, sf.out.message)
+		}
+	}
+	return nil
+}
+
+// rollback restores the state from before the transaction started.
+func (t *txn) rollback() error {
+	var errs []error
+
+	for _, sf := range t.staged {
+		if err := os.Remove(sf.tmp); err != nil && !os.IsNotExist(err) {
+			errs = append(errs, err)
+		}
+	}
+
+	for i := len(t.committed) - 1; i >= 0; i-- {
+		sf := t.committed[i]
+		if !sf.existed {
+			if err := os.Remove(sf.out.path); err != nil && !os.IsNotExist(err) {
+				errs = append(errs, err)
+			}
+			continue
+		}
+		tmp, err := writeTemp(filepath.Dir(sf.out.path), sf.backup, sf.mode)
+		if err == nil {
+			err = os.Rename(tmp, sf.out.path)
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("restore %s: %w", sf.out.path, err))
+		}
+	}
+
+	// Remove the deepest directories first so parents end up empty too.
+	sort.Sort(sort.Reverse(sort.StringSlice(t.dirs)))
+	for _, dir := range t.dirs {
+		_ = os.Remove(dir)
+	}
+
+	return errors.Join(errs...)
+}
+
+// mkdirAll behaves like os.MkdirAll but remembers which directories it
+// created so that rollback can remove them.
+func (t *txn) mkdirAll(dir string) error {
+	var missing []string
+	for d := dir; ; d = filepath.Dir(d) {
+		if _, err := os.Stat(d); err == nil {
+			break
+		}
+		missing = append(missing, d)
+		if filepath.Dir(d) == d {
+			break
+		}
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+	t.dirs = append(t.dirs, missing...)
+	return nil
+}
+
+func writeTemp(dir string, content []byte, mode os.FileMode) (string, error) {
+	f, err := os.CreateTemp(dir, ".dashgen-*.tmp")
+	if err != nil {
+		return "", err
+	}
+	name := f.Name()
+	if _, err := f.Write(content); err != nil {
+		f.Close()
+		os.Remove(name)
+		return "", err
+	}
+	if err := f.Chmod(mode); err != nil {
+		f.Close()
+		os.Remove(name)
+		return "", err
+	}
+	if err := f.Close(); err != nil {
+		os.Remove(name)
+		return "", err
+	}
+	return name, nil
+}
@@ -0,0 +1,65 @@
+package generator
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// TestTxnRollback fails the third rename of a transaction and checks that
+// the project is back to its state from before the transaction.
+func TestTxnRollback(t *testing.T) {
+	root := t.TempDir()
+	existing := filepath.Join(root, "model/user/init.go")
+	if err := os.MkdirAll(filepath.Dir(existing), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(existing, []byte("old"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	created := filepath.Join(root, "model/order/init.go")
+	blocked := filepath.Join(root, "model/user/repository.go")
+
+	tx := &txn{log: io.Discard}
+	err := tx.stage([]output{
+		{path: existing, content: []byte("new")},
+		{path: created, content: []byte("new")},
+		{path: blocked, content: []byte("new")},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// A non-empty directory cannot be replaced by a rename.
+	if err := os.MkdirAll(filepath.Join(blocked, "keep"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := tx.apply(); err == nil {
+		t.Fatal("apply() succeeded")
+	}
+	if len(tx.committed) != 2 {
+		t.Fatalf("%d files committed before the failure, want 2", len(tx.committed))
+	}
+	if err := tx.rollback(); err != nil {
+		t.Fatal(err)
+	}
+
+	if b, err := os.ReadFile(existing); err != nil || string(b) != "old" {
+		t.Errorf("%s = %q, %v after rollback, want its old content", existing, b, err)
+	}
+	if info, err := os.Stat(existing); err != nil || info.Mode().Perm() != 0o600 {
+		t.Errorf("%s lost its mode: %v, %v", existing, info.Mode(), err)
+	}
+	if _, err := os.Stat(filepath.Dir(created)); !os.IsNotExist(err) {
+		t.Errorf("directory of the created file survived the rollback: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(blocked, "keep")); err != nil {
+		t.Errorf("rollback removed a directory it did not create: %v", err)
+	}
+	for _, dir := range []string{filepath.Dir(existing), filepath.Join(root, "model")} {
+		tmps, err := filepath.Glob(filepath.Join(dir, ".dashgen-*.tmp"))
+		if err != nil || len(tmps) > 0 {
+			t.Errorf("staged files left in %s: %v, %v", dir, tmps, err)
+		}
+	}
+}
@@ -0,0 +1,187 @@
+// Package gomod locates the Go modules of a project from its go.mod and
+// go.work files and maps directories to import paths.
+package gomod
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// Module is a Go module on disk.
+type Module struct {
+	Path string // module path from the module directive
+	Dir  string // absolute directory holding go.mod
+}
+
+// Resolver maps directories to import paths using the modules of a
+// workspace, or the single module found above the start directory.
+type Resolver struct {
+	Modules []Module
+}
+
+// NewResolver finds the modules that apply to dir. A go.work file above dir
+// wins, unless GOWORK=off, and contributes all of its used modules;
+// otherwise the nearest go.mod above dir is used.
+func NewResolver(dir string) (*Resolver, error) {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	if os.Getenv("GOWORK") != "off" {
+		work := os.Getenv("GOWORK")
+		if work == "" {
+			work = findUp(dir, "go.work")
+		}
+		if work != "" {
+			mods, err := loadWork(work)
+			if err != nil {
+				return nil, err
+			}
+			return &Resolver{Modules: mods}, nil
+		}
+	}
+
+	gomod := findUp(dir, "go.mod")
+	if gomod == "" {
+		return nil, fmt.Errorf("no go.mod found in %s or any parent directory", dir)
+	}
+	mod, err := loadModule(filepath.Dir(gomod))
+	if err != nil {
+		return nil, err
+	}
+	return &Resolver{Modules: []Module{mod}}, nil
+}
+
+// ImportPath returns the import path of the package in dir, using the
+// innermost module containing it.
+func (r *Resolver) ImportPath(dir string) (string, error) {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return "", err
+	}
+
+	var best *Module
+	for i, m := range r.Modules {
+		if contains(m.Dir, dir) && (best == nil || len(m.Dir) > len(best.Dir)) {
+			best = &r.Modules[i]
+		}
+	}
+	if best == nil {
+		return "", fmt.Errorf("%s is not inside any known module", dir)
+	}
+
+	rel, err := filepath.Rel(best.Dir, dir)
+	if err != nil {
+		return "", err
+	}
+	if rel == "." {
+		return best.Path, nil
+	}
+	return best.Path + "/" + filepath.ToSlash(rel), nil
+}
+
+// findUp returns the first file called name in dir or its parents.
+func findUp(dir, name string) string {
+	for {
+		p := filepath.Join(dir, name)
+		if info, err := os.Stat(p); err == nil && !info.IsDir() {
+			return p
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return ""
+		}
+		dir = parent
+	}
+}
+
+func contains(parent, dir string) bool {
+	rel, err := filepath.Rel(parent, dir)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+// loadModule reads the module path from dir/go.mod.
+func loadModule(dir string) (Module, error) {
+	p := filepath.Join(dir, "go.mod")
+	data, err := os.ReadFile(p)
+	if err != nil {
+		return Module{}, err
+	}
+	args := directives(data, "module")
+	if len(args) == 0 {
+		return Module{}, fmt.Errorf("%s: no module directive", p)
+	}
+	return Module{Path: args[0], Dir: dir}, nil
+}
+
+// loadWork returns the modules used by the go.work file at p.
+func loadWork(p string) ([]Module, error) {
+	data, err := os.ReadFile(p)
+	if err != nil {
+		return nil, err
+	}
+	var mods []Module
+	for _, use := range directives(data, "use") {
+		dir := use
+		if !filepath.IsAbs(dir) {
+			dir = filepath.Join(filepath.Dir(p), dir)
+		}
+		mod, err := loadModule(dir)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", p, err)
+		}
+		mods = append(mods, mod)
+	}
+	if len(mods) == 0 {
+		return nil, fmt.Errorf("%s: no use directive", p)
+	}
+	return mods, nil
+}
+
+// directives returns the arguments of every verb directive in a go.mod or
+// go.work file, both in the single line form and inside a block.
+func directives(data []byte, verb string) []string {
+	var args []string
+	inBlock := false
+	sc := bufio.NewScanner(bytes.NewReader(data))
+	for sc.Scan() {
+		line := sc.Text()
+		if i := strings.Index(line, "//"); i >= 0 {
+			line = line[:i]
+		}
+		line = strings.TrimSpace(line)
+
+		switch {
+		case line == "":
+		case inBlock && line == ")":
+			inBlock = false
+		case inBlock:
+			args = append(args, unquote(line))
+		default:
+			fields := strings.Fields(line)
+			if fields[0] != verb {
+				continue
+			}
+			rest := strings.TrimSpace(strings.TrimPrefix(line, verb))
+			if rest == "(" {
+				inBlock = true
+			} else if rest != "" {
+				args = append(args, unquote(rest))
+			}
+		}
+	}
+	return args
+}
+
+func unquote(s string) string {
+	if u, err := strconv.Unquote(s); err == nil {
+		return u
+	}
+	return s
+}
@@ -0,0 +1,245 @@
+// Package naming converts Go identifiers between naming conventions. Words
+// are split the way Go names are written: common initialisms such as ID,
+// URL or HTTP stay one word ("HTTPLog" is "HTTP" + "Log", "UserIDs" is
+// "User" + "IDs") and non-ASCII letters are handled like ASCII ones.
+package naming
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// initialisms are written in upper case when they appear in Go names.
+var initialisms = map[string]bool{
+	"ACL": true, "API": true, "ASCII": true, "CPU": true, "CSS": true,
+	"DB": true, "DNS": true, "EOF": true, "GUID": true, "HTML": true,
+	"HTTP": true, "HTTPS": true, "ID": true, "IP": true, "JSON": true,
+	"JWT": true, "LHS": true, "QPS": true, "RAM": true, "RHS": true,
+	"RPC": true, "SKU": true, "SLA": true, "SMTP": true, "SQL": true,
+	"SSH": true, "TCP": true, "TLS": true, "TTL": true, "UDP": true,
+	"UI": true, "UID": true, "URI": true, "URL": true, "UTF8": true,
+	"UUID": true, "VM": true, "XML": true, "XMPP": true, "XSRF": true,
+	"XSS": true,
+}
+
+// IsInitialism reports whether word is a known initialism such as "ID".
+func IsInitialism(word string) bool {
+	return initialisms[strings.ToUpper(word)]
+}
+
+// Words splits s into its words. Underscores, hyphens, dots and spaces
+// separate words, and so do case changes.
+func Words(s string) []string {
+	var words []string
+	for _, part := range strings.FieldsFunc(s, func(r rune) bool {
+		return r == '_' || r == '-' || r == '.' || unicode.IsSpace(r)
+	}) {
+		words = append(words, splitCase(part)...)
+	}
+	return words
+}
+
+// splitCase splits a single camel or pascal case token.
+func splitCase(s string) []string {
+	runes := []rune(s)
+	var words []string
+	start := 0
+	for i := 1; i < len(runes); i++ {
+		prev, cur := runes[i-1], runes[i]
+		switch {
+		case unicode.IsLower(prev) && unicode.IsUpper(cur),
+			unicode.IsDigit(prev) && unicode.IsUpper(cur):
+			// userID -> user|ID, Base64Value -> Base64|Value
+			words = append(words, string(runes[start:i]))
+			start = i
+		case unicode.IsUpper(prev) && unicode.IsUpper(cur) &&
+			i+1 < len(runes) && unicode.IsLower(runes[i+1]):
+			// HTTPLog -> HTTP|Log, but keep plural initialisms: UserIDs
+			if run := string(runes[start : i+1]); initialisms[run] && isPluralS(runes, i+1) {
+				words = append(words, string(runes[start:i+2]))
+				start = i + 2
+				i = start
+				continue
+			}
+			words = append(words, string(runes[start:i]))
+			start = i
+		}
+	}
+	if start < len(runes) {
+		words = append(words, string(runes[start:]))
+	}
+	return words
+}
+
+// isPluralS reports whether runes[i] is an "s" ending a word.
+func isPluralS(runes []rune, i int) bool {
+	return runes[i] == 's' && (i+1 == len(runes) || !unicode.IsLower(runes[i+1]))
+}
+
+// Snake returns s in snake_case: "APIKey" becomes "api_key".
+func Snake(s string) string {
+	return join(Words(s), "_", strings.ToLower)
+}
+
+// Kebab returns s in kebab-case: "APIKey" becomes "api-key".
+func Kebab(s string) string {
+	return join(Words(s), "-", strings.ToLower)
+}
+
+// Screaming returns s in SCREAMING_SNAKE_CASE: "APIKey" becomes "API_KEY".
+func Screaming(s string) string {
+	return join(Words(s), "_", strings.ToUpper)
+}
+
+// Pascal returns s in PascalCase with Go initialisms: "api_key" becomes
+// "APIKey".
+func Pascal(s string) string {
+	return join(Words(s), "", title)
+}
+
+// Camel returns s in camelCase with Go initialisms: "URLMapping" becomes
+// "urlMapping" and "user_id" becomes "userID".
+func Camel(s string) string {
+	words := Words(s)
+	if len(words) == 0 {
+		return ""
+	}
+	return strings.ToLower(words[0]) + join(words[1:], "", title)
+}
+
+// title upper-cases an initialism and capitalizes any other word.
+func title(word string) string {
+	if IsInitialism(word) {
+		return strings.ToUpper(word)
+	}
+	// plural initialisms such as IDs
+	if strings.HasSuffix(word, "s") && IsInitialism(word[:len(word)-1]) {
+		return strings.ToUpper(word[:len(word)-1]) + "s"
+	}
+	r, size := utf8.DecodeRuneInString(word)
+	return string(unicode.ToUpper(r)) + strings.ToLower(word[size:])
+}
+
+func join(words []string, sep string, f func(string) string) string {
+	out := make([]string, len(words))
+	for i, w := range words {
+		out[i] = f(w)
+	}
+	return strings.Join(out, sep)
+}
+
+// irregular maps singular nouns whose plural does not follow the rules.
+var irregular = map[string]string{
+	"child":  "children",
+	"person": "people",
+	"man":    "men",
+	"woman":  "women",
+	"mouse":  "mice",
+	"goose":  "geese",
+}
+
+// Plural returns the English plural of the last word of s, keeping the
+// spelling of everything around it: "Category" becomes "Categories",
+// "OrderAddress" becomes "OrderAddresses" and "user_" becomes "users_".
+func Plural(s string) string {
+	head, last, tail := splitLast(s)
+	if last == "" {
+		return s + "s"
+	}
+	return head + pluralWord(last) + tail
+}
+
+func pluralWord(word string) string {
+	lower := strings.ToLower(word)
+	if p, ok := irregular[lower]; ok {
+		return matchWord(p, word)
+	}
+	switch {
+	case IsInitialism(word):
+		return word + "s"
+	case isPluralInitialism(word):
+		return word
+	case strings.HasSuffix(lower, "y") && len(lower) > 1 && !isVowel(lower[len(lower)-2]):
+		return word[:len(word)-1] + matchCase("ies", word[len(word)-1:])
+	case strings.HasSuffix(lower, "s"), strings.HasSuffix(lower, "x"), strings.HasSuffix(lower, "z"),
+		strings.HasSuffix(lower, "ch"), strings.HasSuffix(lower, "sh"):
+		return word + matchCase("es", word[len(word)-1:])
+	}
+	return word + matchCase("s", word[len(word)-1:])
+}
+
+// Singular reverses Plural: "Categories" becomes "Category". Words ending
+// in -us, -is or -ss, such as "Status", "Analysis" or "Address", are
+// already singular.
+func Singular(s string) string {
+	head, last, tail := splitLast(s)
+	return head + singularWord(last) + tail
+}
+
+func singularWord(word string) string {
+	lower := strings.ToLower(word)
+	for sing, plur := range irregular {
+		if lower == plur {
+			return matchWord(sing, word)
+		}
+	}
+	switch {
+	case isPluralInitialism(word):
+		return word[:len(word)-1]
+	case strings.HasSuffix(lower, "ies") && len(lower) > 3:
+		return word[:len(word)-3] + matchCase("y", word[len(word)-3:])
+	case strings.HasSuffix(lower, "ouses"):
+		return word[:len(word)-1]
+	case strings.HasSuffix(lower, "sses"), strings.HasSuffix(lower, "uses"), strings.HasSuffix(lower, "xes"),
+		strings.HasSuffix(lower, "zes"), strings.HasSuffix(lower, "ches"), strings.HasSuffix(lower, "shes"):
+		return word[:len(word)-2]
+	case strings.HasSuffix(lower, "ss"), strings.HasSuffix(lower, "us"), strings.HasSuffix(lower, "is"):
+		return word
+	case strings.HasSuffix(lower, "s") && len(lower) > 1:
+		return word[:len(word)-1]
+	}
+	return word
+}
+
+// isPluralInitialism reports whether word is an initialism followed by a
+// lower case "s", such as "IDs" or "URLs".
+func isPluralInitialism(word string) bool {
+	return len(word) > 1 && strings.HasSuffix(word, "s") && IsInitialism(word[:len(word)-1])
+}
+
+// splitLast splits s around its last word: the text before it, the word
+// and the separators after it.
+func splitLast(s string) (head, last, tail string) {
+	words := Words(s)
+	if len(words) == 0 {
+		return s, "", ""
+	}
+	last = words[len(words)-1]
+	i := strings.LastIndex(s, last)
+	return s[:i], last, s[i+len(last):]
+}
+
+// matchCase upper-cases suffix when ref is all upper case.
+func matchCase(suffix, ref string) string {
+	if ref != "" && strings.ToUpper(ref) == ref && strings.ToLower(ref) != ref {
+		return strings.ToUpper(suffix)
+	}
+	return suffix
+}
+
+// matchWord spells word with the capitalization of ref.
+func matchWord(word, ref string) string {
+	r, _ := utf8.DecodeRuneInString(ref)
+	switch {
+	case matchCase("x", ref) == "X":
+		return strings.ToUpper(word)
+	case unicode.IsUpper(r):
+		return title(word)
+	}
+	return word
+}
+
+func isVowel(b byte) bool {
+	return strings.IndexByte("aeiou", b) >= 0
+}
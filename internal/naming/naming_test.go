@@ -0,0 +1,115 @@
+package naming
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestWords(t *testing.T) {
+	tests := []struct {
+		in   string
+		want []string
+	}{
+		{"userID", []string{"user", "ID"}},
+		{"HTTPLog", []string{"HTTP", "Log"}},
+		{"URLMapping", []string{"URL", "Mapping"}},
+		{"UserIDs", []string{"User", "IDs"}},
+		{"URLsByHost", []string{"URLs", "By", "Host"}},
+		{"Base64Value", []string{"Base64", "Value"}},
+		{"OAuth2Token", []string{"O", "Auth2", "Token"}},
+		{"api_key", []string{"api", "key"}},
+		{"order-item.v2", []string{"order", "item", "v2"}},
+		{"ÜberName", []string{"Über", "Name"}},
+		{"", nil},
+	}
+	for _, tt := range tests {
+		if got := Words(tt.in); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Words(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestConversions(t *testing.T) {
+	tests := []struct {
+		in, snake, kebab, screaming, pascal, camel string
+	}{
+		{"APIKey", "api_key", "api-key", "API_KEY", "APIKey", "apiKey"},
+		{"HTTPLog", "http_log", "http-log", "HTTP_LOG", "HTTPLog", "httpLog"},
+		{"URLMapping", "url_mapping", "url-mapping", "URL_MAPPING", "URLMapping", "urlMapping"},
+		{"user_id", "user_id", "user-id", "USER_ID", "UserID", "userID"},
+		{"UserIDs", "user_ids", "user-ids", "USER_IDS", "UserIDs", "userIDs"},
+		{"order_item", "order_item", "order-item", "ORDER_ITEM", "OrderItem", "orderItem"},
+	}
+	for _, tt := range tests {
+		for _, c := range []struct {
+			name string
+			f    func(string) string
+			want string
+		}{
+			{"Snake", Snake, tt.snake},
+			{"Kebab", Kebab, tt.kebab},
+			{"Screaming", Screaming, tt.screaming},
+			{"Pascal", Pascal, tt.pascal},
+			{"Camel", Camel, tt.camel},
+		} {
+			if got := c.f(tt.in); got != c.want {
+				t.Errorf("%s(%q) = %q, want %q", c.name, tt.in, got, c.want)
+			}
+		}
+	}
+}
+
+func TestPluralSingular(t *testing.T) {
+	tests := []struct {
+		singular, plural string
+	}{
+		{"User", "Users"},
+		{"Category", "Categories"},
+		{"Day", "Days"},
+		{"Address", "Addresses"},
+		{"OrderAddress", "OrderAddresses"},
+		{"Status", "Statuses"},
+		{"Bus", "Buses"},
+		{"Box", "Boxes"},
+		{"Batch", "Batches"},
+		{"Wish", "Wishes"},
+		{"Person", "People"},
+		{"SalesPerson", "SalesPeople"},
+		{"Child", "Children"},
+		{"Mouse", "Mice"},
+		{"ID", "IDs"},
+		{"UserID", "UserIDs"},
+		{"URL", "URLs"},
+		{"UI", "UIs"},
+		{"HTTPLog", "HTTPLogs"},
+		{"user_", "users_"},
+		{"order_item", "order_items"},
+		{"CATEGORY", "CATEGORIES"},
+		{"ADDRESS", "ADDRESSES"},
+	}
+	for _, tt := range tests {
+		if got := Plural(tt.singular); got != tt.plural {
+			t.Errorf("Plural(%q) = %q, want %q", tt.singular, got, tt.plural)
+		}
+		if got := Singular(tt.plural); got != tt.singular {
+			t.Errorf("Singular(%q) = %q, want %q", tt.plural, got, tt.singular)
+		}
+	}
+}
+
+func TestPluralInitialisms(t *testing.T) {
+	// Plural initialisms are already plural.
+	for _, s := range []string{"IDs", "UserIDs", "URLs"} {
+		if got := Plural(s); got != s {
+			t.Errorf("Plural(%q) = %q, want it unchanged", s, got)
+		}
+	}
+}
+
+func TestSingularOfSingular(t *testing.T) {
+	for _, s := range []string{"Status", "Bus", "Analysis", "Address", "Class", "User", "Category", "user_id"} {
+		if got := Singular(s); got != s {
+			t.Errorf("Singular(%q) = %q, want it unchanged", s, got)
+		}
+	}
+}
@@ -0,0 +1,58 @@
+package parser
+
+import (
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+)
+
+// Filter returns the entities whose name matches one of include (all when
+// include is empty) and none of exclude, in order. Patterns are entity
+// names or globs such as "Order*". A pattern that matches no entity is
+// reported as an error listing the available entities; the filtered
+// entities are returned either way.
+func Filter(entities []Entity, include, exclude []string) ([]Entity, error) {
+	for _, p := range append(append([]string(nil), include...), exclude...) {
+		if _, err := path.Match(p, ""); err != nil {
+			return nil, fmt.Errorf("invalid entity pattern %q: %w", p, err)
+		}
+	}
+
+	used := map[string]bool{}
+	matches := func(name string, patterns []string) bool {
+		found := false
+		for _, p := range patterns {
+			if ok, _ := path.Match(p, name); ok {
+				used[p] = true
+				found = true
+			}
+		}
+		return found
+	}
+
+	var out []Entity
+	for _, e := range entities {
+		in := len(include) == 0 || matches(e.Name, include)
+		if matches(e.Name, exclude) || !in {
+			continue
+		}
+		out = append(out, e)
+	}
+
+	var unknown []string
+	for _, p := range append(append([]string(nil), include...), exclude...) {
+		if !used[p] {
+			unknown = append(unknown, p)
+		}
+	}
+	if len(unknown) > 0 {
+		names := make([]string, len(entities))
+		for i, e := range entities {
+			names[i] = e.Name
+		}
+		sort.Strings(names)
+		return out, fmt.Errorf("no entity matches %s (available: %s)", strings.Join(unknown, ", "), strings.Join(names, ", "))
+	}
+	return out, nil
+}
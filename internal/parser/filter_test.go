@@ -0,0 +1,47 @@
+package parser
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestFilter(t *testing.T) {
+	var entities []Entity
+	for _, name := range []string{"User", "Order", "OrderItem", "Invoice"} {
+		entities = append(entities, Entity{Name: name})
+	}
+	tests := []struct {
+		name             string
+		include, exclude []string
+		want             string // entity names, comma separated
+		err              string
+	}{
+		{name: "no filters", want: "User,Order,OrderItem,Invoice"},
+		{name: "names", include: []string{"OrderItem", "User"}, want: "User,OrderItem"},
+		{name: "glob", include: []string{"Order*"}, want: "Order,OrderItem"},
+		{name: "exclude", exclude: []string{"Order*"}, want: "User,Invoice"},
+		{name: "include and exclude", include: []string{"Order*", "User"}, exclude: []string{"OrderItem"}, want: "User,Order"},
+		{name: "case sensitive", include: []string{"user"}, want: "", err: "no entity matches user (available: Invoice, Order, OrderItem, User)"},
+		{name: "unknown name", include: []string{"User", "Customer"}, want: "User", err: "no entity matches Customer (available: Invoice, Order, OrderItem, User)"},
+		{name: "unknown exclude", exclude: []string{"Cust*", "Ship*"}, want: "User,Order,OrderItem,Invoice", err: "no entity matches Cust*, Ship*"},
+		{name: "invalid pattern", include: []string{"Order["}, err: `invalid entity pattern "Order["`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Filter(entities, tt.include, tt.exclude)
+			if tt.err == "" && err != nil {
+				t.Fatal(err)
+			}
+			if tt.err != "" && (err == nil || !strings.Contains(err.Error(), tt.err)) {
+				t.Fatalf("Filter() error = %v, want %q", err, tt.err)
+			}
+			names := make([]string, len(got))
+			for i, e := range got {
+				names[i] = e.Name
+			}
+			if s := strings.Join(names, ","); s != tt.want {
+				t.Errorf("Filter() = %s, want %s", s, tt.want)
+			}
+		})
+	}
+}
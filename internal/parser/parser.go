@@ -1,11 +1,16 @@
 package parser
 
 import (
+	"errors"
+	"fmt"
 	"go/ast"
 	"go/parser"
 	"go/token"
 	"path/filepath"
+	"slices"
 	"strings"
+
+	"github.com/gotech-hub/dashgen/internal/naming"
 )
 
 type Field struct {
@@ -18,10 +23,20 @@ type Field struct {
 }
 
 type Index struct {
-	Fields []IndexField // Fields in the index
-	Unique bool         // Whether the index is unique
-	Sparse bool         // Whether the index is sparse
-	Name   string       // Custom index name (optional)
+	Fields  []IndexField // Fields in the index
+	Unique  bool         // Whether the index is unique
+	Sparse  bool         // Whether the index is sparse
+	Name    string       // Custom index name (optional)
+	Partial []Condition  // Only index documents matching all conditions (optional)
+	Cursor  bool         // The sort order of cursor pagination
+}
+
+// Condition is an equality condition of a partial index, written
+// field=value. Value is the literal as written: true, false, null, a number
+// or a string.
+type Condition struct {
+	Field string
+	Value string
 }
 
 type IndexField struct {
@@ -31,12 +46,56 @@ type IndexField struct {
 }
 
 type Entity struct {
-	PkgPath string
+	PkgPath string // display path of the model package, e.g. "model/user"
+	Dir     string // absolute directory of the file declaring the entity
+	Source  string // absolute path of the file declaring the entity
+	Package string // package name from the package clause
 	Name    string
 	Plural  string
 	DBName  string
 	Fields  []Field
-	Indexes []Index // Compound indexes defined via comments
+	Indexes []Index  // Compound indexes defined via comments
+	Layers  []string // layers to generate, from "@entity layers:a,b"; empty means all
+}
+
+// Discover returns the data.go files of the model packages below root.
+func Discover(root string) ([]string, error) {
+	pattern := filepath.Join(root, "model/**/data.go")
+	matches, err := filepath.Glob(pattern)
+	if err != nil {
+		return nil, err
+	}
+	if len(matches) == 0 {
+		return nil, fmt.Errorf("no data.go found under %s", pattern)
+	}
+	return matches, nil
+}
+
+// FileError is the failure to parse a single data.go file.
+type FileError struct {
+	Path string
+	Err  error
+}
+
+func (e *FileError) Error() string { return fmt.Sprintf("parse %s: %v", e.Path, e.Err) }
+
+func (e *FileError) Unwrap() error { return e.Err }
+
+// ParseFiles parses every file and returns the entities of those that
+// parsed, in order. Failures do not stop the other files from being parsed;
+// they are returned joined as *FileError values.
+func ParseFiles(paths []string) ([]Entity, error) {
+	var entities []Entity
+	var errs []error
+	for _, p := range paths {
+		e, err := ParseDataGo(p)
+		if err != nil {
+			errs = append(errs, &FileError{Path: p, Err: err})
+			continue
+		}
+		entities = append(entities, e...)
+	}
+	return entities, errors.Join(errs...)
 }
 
 func ParseDataGo(path string) ([]Entity, error) {
@@ -47,7 +106,13 @@ func ParseDataGo(path string) ([]Entity, error) {
 	}
 
 	pkgRel := relModelPath(path)
+	source, err := filepath.Abs(path)
+	if err != nil {
+		return nil, err
+	}
+	dir := filepath.Dir(source)
 	var out []Entity
+	var annotationErr error
 
 	ast.Inspect(f, func(n ast.Node) bool {
 		gd, ok := n.(*ast.GenDecl)
@@ -67,6 +132,7 @@ func ParseDataGo(path string) ([]Entity, error) {
 
 			var isEntity bool
 			var dbName string
+			var layers []string
 			var indexes []Index
 
 			// Check both GenDecl.Doc and TypeSpec.Doc
@@ -87,10 +153,19 @@ func ParseDataGo(path string) ([]Entity, error) {
 							if strings.HasPrefix(p, "db:") {
 								dbName = strings.TrimPrefix(p, "db:")
 							}
+							if strings.HasPrefix(p, "layers:") {
+								layers = strings.Split(strings.TrimPrefix(p, "layers:"), ",")
+								if slices.Contains(layers, "") && annotationErr == nil {
+									annotationErr = fmt.Errorf("type %s: @entity %s: empty layer name", ts.Name.Name, p)
+								}
+							}
 						}
 					} else if strings.HasPrefix(text, "@index") {
-						// Parse index definition: @index field1:1,field2:-1 unique sparse name:custom_name
-						idx := parseIndexComment(text)
+						// Parse index definition: @index field1:1,field2:-1 unique sparse cursor name:custom_name partial:field=value
+						idx, err := parseIndexComment(text)
+						if err != nil && annotationErr == nil {
+							annotationErr = fmt.Errorf("type %s: %w", ts.Name.Name, err)
+						}
 						if idx != nil {
 							indexes = append(indexes, *idx)
 						}
@@ -120,15 +195,22 @@ func ParseDataGo(path string) ([]Entity, error) {
 			}
 			out = append(out, Entity{
 				PkgPath: pkgRel,
+				Dir:     dir,
+				Source:  source,
+				Package: f.Name.Name,
 				Name:    entName,
-				Plural:  naivePlural(entName),
+				Plural:  naming.Plural(entName),
 				DBName:  dbName,
 				Fields:  fields,
 				Indexes: indexes,
+				Layers:  layers,
 			})
 		}
 		return true
 	})
+	if annotationErr != nil {
+		return nil, annotationErr
+	}
 	return out, nil
 }
 
@@ -198,35 +280,23 @@ func exprString(e ast.Expr) string {
 }
 
 func defaultDBName(name string) string {
-	var b []rune
-	for i, r := range name {
-		if i > 0 && r >= 'A' && r <= 'Z' {
-			b = append(b, '_')
-		}
-		b = append(b, rune(strings.ToLower(string(r))[0]))
-	}
-	return string(b) + "s"
-}
-
-func naivePlural(s string) string {
-	if strings.HasSuffix(s, "y") {
-		return s[:len(s)-1] + "ies"
-	}
-	return s + "s"
+	return naming.Snake(naming.Plural(name))
 }
 
 // parseIndexComment parses index definition from comment
-// Format: @index field1:1,field2:-1 unique sparse name:custom_name
-func parseIndexComment(comment string) *Index {
+// Format: @index field1:1,field2:-1 unique sparse cursor name:custom_name
+// partial:field=value,...
+// A partial condition that is not field=value is an error.
+func parseIndexComment(comment string) (*Index, error) {
 	// Remove @index prefix
 	comment = strings.TrimSpace(strings.TrimPrefix(comment, "@index"))
 	if comment == "" {
-		return nil
+		return nil, nil
 	}
 
 	parts := strings.Fields(comment)
 	if len(parts) == 0 {
-		return nil
+		return nil, nil
 	}
 
 	index := &Index{}
@@ -278,10 +348,20 @@ func parseIndexComment(comment string) *Index {
 			index.Unique = true
 		case part == "sparse":
 			index.Sparse = true
+		case part == "cursor":
+			index.Cursor = true
 		case strings.HasPrefix(part, "name:"):
 			index.Name = strings.TrimPrefix(part, "name:")
+		case strings.HasPrefix(part, "partial:"):
+			for _, cond := range strings.Split(strings.TrimPrefix(part, "partial:"), ",") {
+				field, value, ok := strings.Cut(cond, "=")
+				if !ok || field == "" {
+					return nil, fmt.Errorf("@index %s: partial condition %q is not field=value", fieldDefs, cond)
+				}
+				index.Partial = append(index.Partial, Condition{Field: field, Value: value})
+			}
 		}
 	}
 
-	return index
+	return index, nil
 }
@@ -0,0 +1,251 @@
+package parser
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// writeFiles writes files, relative paths mapped to their content, below a
+// new directory and returns it.
+func writeFiles(t *testing.T, files map[string]string) string {
+	t.Helper()
+	root := t.TempDir()
+	for name, content := range files {
+		path := filepath.Join(root, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return root
+}
+
+// parseSource parses a data.go with src and returns its entities.
+func parseSource(t *testing.T, src string) ([]Entity, error) {
+	t.Helper()
+	root := writeFiles(t, map[string]string{"model/item/data.go": src})
+	return ParseDataGo(filepath.Join(root, "model/item/data.go"))
+}
+
+const userData = `package user
+
+// @entity db:users
+type User struct {
+	ID     string ` + "`json:\"id\" bson:\"_id\"`" + `
+	UserID string ` + "`json:\"user_id\" bson:\"user_id\" index:\"unique\"`" + `
+	Email  *string ` + "`json:\"email\" bson:\"email\" validate:\"required,email\"`" + `
+}
+
+// Settings is not an entity.
+type Settings struct {
+	Theme string
+}
+`
+
+func TestDiscover(t *testing.T) {
+	root := writeFiles(t, map[string]string{
+		"model/user/data.go":    userData,
+		"model/order/data.go":   "package order\n",
+		"model/order/other.go":  "package order\n",
+		"internal/misc/data.go": "package misc\n",
+	})
+	got, err := Discover(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{
+		filepath.Join(root, "model/order/data.go"),
+		filepath.Join(root, "model/user/data.go"),
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Discover() = %v, want %v", got, want)
+	}
+
+	if _, err := Discover(t.TempDir()); err == nil {
+		t.Error("Discover() of a project without models succeeded")
+	}
+}
+
+func TestParseDataGo(t *testing.T) {
+	entities, err := parseSource(t, userData)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entities) != 1 {
+		t.Fatalf("got %d entities, want 1", len(entities))
+	}
+	e := entities[0]
+	if e.Name != "User" || e.Plural != "Users" || e.DBName != "users" || e.Package != "user" || e.PkgPath != "model/item" {
+		t.Errorf("entity = %+v", e)
+	}
+	want := []Field{
+		{Name: "ID", Type: "string", JSONTag: "id", BSONTag: "_id"},
+		{Name: "UserID", Type: "string", JSONTag: "user_id", BSONTag: "user_id", Index: "unique"},
+		{Name: "Email", Type: "*string", JSONTag: "email", BSONTag: "email", Validate: "required,email"},
+	}
+	if !reflect.DeepEqual(e.Fields, want) {
+		t.Errorf("Fields = %+v, want %+v", e.Fields, want)
+	}
+}
+
+func TestDefaultDBName(t *testing.T) {
+	entities, err := parseSource(t, "package item\n\n// @entity\ntype HTTPLog struct{}\n\n// @entity\ntype Category struct{}\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, e := range entities {
+		got = append(got, e.DBName)
+	}
+	if want := []string{"http_logs", "categories"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("DBName = %v, want %v", got, want)
+	}
+}
+
+func TestParseFiles(t *testing.T) {
+	root := writeFiles(t, map[string]string{
+		"model/user/data.go":   userData,
+		"model/broken/data.go": "package broken\n\n// @entity\ntype Broken struct {\n",
+		"model/bad/data.go":    "not go",
+	})
+	paths := []string{
+		filepath.Join(root, "model/broken/data.go"),
+		filepath.Join(root, "model/user/data.go"),
+		filepath.Join(root, "model/bad/data.go"),
+		filepath.Join(root, "model/missing/data.go"),
+	}
+	entities, err := ParseFiles(paths)
+	if len(entities) != 1 || entities[0].Name != "User" {
+		t.Errorf("ParseFiles() = %+v, want the entities of the valid file", entities)
+	}
+	if err == nil {
+		t.Fatal("ParseFiles() succeeded")
+	}
+
+	joined, ok := err.(interface{ Unwrap() []error })
+	if !ok {
+		t.Fatalf("error %v is not joined", err)
+	}
+	var failed []string
+	for _, e := range joined.Unwrap() {
+		var fe *FileError
+		if !errors.As(e, &fe) {
+			t.Fatalf("error %v is not a *FileError", e)
+		}
+		if fe.Err == nil {
+			t.Errorf("*FileError for %s has no cause", fe.Path)
+		}
+		failed = append(failed, fe.Path)
+	}
+	if !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("error %v does not wrap the missing file's fs.ErrNotExist", err)
+	}
+	want := []string{paths[0], paths[2], paths[3]}
+	if !reflect.DeepEqual(failed, want) {
+		t.Errorf("failed files = %v, want %v", failed, want)
+	}
+}
+
+func TestEntityLayers(t *testing.T) {
+	entities, err := parseSource(t, "package item\n\n// @entity db:items layers:model,repository\ntype Item struct{}\n\n// @entity\ntype Other struct{}\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := entities[0].Layers, []string{"model", "repository"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Layers = %v, want %v", got, want)
+	}
+	if entities[0].DBName != "items" {
+		t.Errorf("DBName = %q next to layers:, want items", entities[0].DBName)
+	}
+	if entities[1].Layers != nil {
+		t.Errorf("Layers = %v without the option, want all layers", entities[1].Layers)
+	}
+
+	for _, option := range []string{"layers:", "layers:model,,api", "layers:model,"} {
+		_, err := parseSource(t, "package item\n\n// @entity "+option+"\ntype Item struct{}\n")
+		if err == nil || !strings.Contains(err.Error(), "empty layer name") {
+			t.Errorf("@entity %s: error = %v, want an empty layer name", option, err)
+		}
+	}
+}
+
+func TestIndexComment(t *testing.T) {
+	tests := []struct {
+		comment string
+		want    *Index
+		err     string
+	}{
+		{comment: "@index", want: nil},
+		{comment: "@index email", want: &Index{Fields: []IndexField{{Name: "email", Direction: 1}}}},
+		{
+			comment: "@index customer_id:1,created_at:-1 unique sparse name:by_customer",
+			want: &Index{
+				Fields: []IndexField{{Name: "customer_id", Direction: 1}, {Name: "created_at", Direction: -1}},
+				Unique: true, Sparse: true, Name: "by_customer",
+			},
+		},
+		{comment: "@index note:text", want: &Index{Fields: []IndexField{{Name: "note", Direction: 1, Type: "text"}}}},
+		{
+			comment: "@index sku:1 unique partial:status=active,price=0,deleted=null,note=it's",
+			want: &Index{
+				Fields: []IndexField{{Name: "sku", Direction: 1}},
+				Unique: true,
+				Partial: []Condition{
+					{Field: "status", Value: "active"},
+					{Field: "price", Value: "0"},
+					{Field: "deleted", Value: "null"},
+					{Field: "note", Value: "it's"},
+				},
+			},
+		},
+		{comment: "@index sku partial:status=", want: &Index{Fields: []IndexField{{Name: "sku", Direction: 1}}, Partial: []Condition{{Field: "status"}}}},
+		{comment: "@index sku partial:status", err: `partial condition "status" is not field=value`},
+		{comment: "@index sku partial:=active", err: `partial condition "=active" is not field=value`},
+		{comment: "@index sku partial:status=a,", err: `partial condition "" is not field=value`},
+		{comment: "@index sku partial:", err: `partial condition "" is not field=value`},
+	}
+	for _, tt := range tests {
+		got, err := parseIndexComment(tt.comment)
+		if tt.err != "" {
+			if err == nil || !strings.Contains(err.Error(), tt.err) {
+				t.Errorf("parseIndexComment(%q) error = %v, want %q", tt.comment, err, tt.err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("parseIndexComment(%q): %v", tt.comment, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("parseIndexComment(%q) = %+v, want %+v", tt.comment, got, tt.want)
+		}
+	}
+}
+
+func TestIndexCommentErrorNamesType(t *testing.T) {
+	_, err := parseSource(t, "package item\n\n// @entity\n// @index sku partial:status\ntype Item struct{}\n")
+	if err == nil || !strings.Contains(err.Error(), "type Item: @index sku: partial condition") {
+		t.Errorf("ParseDataGo() error = %v, want the malformed partial condition of Item", err)
+	}
+}
+
+func TestCursorIndex(t *testing.T) {
+	entities, err := parseSource(t, "package item\n\n// @entity\n// @index created_at:-1,price cursor\n// @index price\ntype Item struct{}\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Index{
+		{Fields: []IndexField{{Name: "created_at", Direction: -1}, {Name: "price", Direction: 1}}, Cursor: true},
+		{Fields: []IndexField{{Name: "price", Direction: 1}}},
+	}
+	if got := entities[0].Indexes; !reflect.DeepEqual(got, want) {
+		t.Errorf("Indexes = %+v, want %+v", got, want)
+	}
+}
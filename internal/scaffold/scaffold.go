@@ -0,0 +1,182 @@
+// Package scaffold writes annotated entity declarations, so that new
+// entities do not depend on hand-written comment formatting.
+package scaffold
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/format"
+	goparser "go/parser"
+	"go/token"
+	"strconv"
+	"strings"
+	"text/template"
+
+	"github.com/gotech-hub/dashgen/internal/naming"
+)
+
+// Field is a field of a scaffolded entity.
+type Field struct {
+	Name     string   // Go field name
+	Type     string   // Go type
+	Validate []string // validate rules, e.g. "required" or "min=0"
+	Index    string   // index tag value: "1", "-1", "text" or "unique"
+}
+
+// Entity describes the entity to scaffold.
+type Entity struct {
+	Name    string // Go type name
+	Package string // package name of the model package
+	DBName  string // collection name; empty keeps the default
+	Fields  []Field
+}
+
+// ParseField parses a field spec of the form name:type[:options], where
+// options is a comma-separated list of validate rules ("required", "email",
+// "min=0", ...) and index options ("unique", "index", "index=-1",
+// "index=text"):
+//
+//	number:string:required,unique
+//	total:float64:min=0
+func ParseField(spec string) (Field, error) {
+	parts := strings.SplitN(spec, ":", 3)
+	if len(parts) < 2 || parts[0] == "" || parts[1] == "" {
+		return Field{}, fmt.Errorf("invalid field %q: want name:type[:options]", spec)
+	}
+	f := Field{Name: naming.Pascal(parts[0]), Type: parts[1]}
+	if !token.IsIdentifier(f.Name) || !token.IsExported(f.Name) {
+		return Field{}, fmt.Errorf("invalid field name %q", parts[0])
+	}
+	if _, err := goparser.ParseExpr(f.Type); err != nil {
+		return Field{}, fmt.Errorf("invalid type %q of field %s", f.Type, parts[0])
+	}
+	if len(parts) == 3 {
+		for _, opt := range strings.Split(parts[2], ",") {
+			switch opt = strings.TrimSpace(opt); {
+			case opt == "":
+			case opt == "unique":
+				f.Index = "unique"
+			case opt == "index":
+				f.Index = "1"
+			case strings.HasPrefix(opt, "index="):
+				f.Index = strings.TrimPrefix(opt, "index=")
+			default:
+				f.Validate = append(f.Validate, opt)
+			}
+		}
+	}
+	return f, nil
+}
+
+// PackageName returns the model package name used for an entity.
+func PackageName(entity string) string {
+	return strings.ReplaceAll(naming.Snake(entity), "_", "")
+}
+
+var declTemplate = template.Must(template.New("entity").Parse(`
+// @entity{{if .DBName}} db:{{.DBName}}{{end}}
+type {{.Name}} struct {
+	ID string ` + "`" + `json:"id" bson:"_id" validate:"required"` + "`" + `
+	{{.Name}}ID string ` + "`" + `json:"{{.IDTag}}" bson:"{{.IDTag}}" validate:"required" index:"unique"` + "`" + `
+{{- range .Fields}}
+	{{.Name}} {{.Type}} ` + "`" + `{{.Tag}}` + "`" + `
+{{- end}}
+	CreatedAt time.Time ` + "`" + `json:"created_at" bson:"created_at"` + "`" + `
+	UpdatedAt time.Time ` + "`" + `json:"updated_at" bson:"updated_at"` + "`" + `
+}
+`))
+
+// DataGo returns the data.go source declaring e. When existing holds the
+// current content of the file, the entity is appended to it; declaring a
+// type that already exists is an error.
+func DataGo(e Entity, existing []byte) ([]byte, error) {
+	if !token.IsIdentifier(e.Name) || !token.IsExported(e.Name) {
+		return nil, fmt.Errorf("invalid entity name %q: want an exported Go identifier", e.Name)
+	}
+
+	type field struct{ Name, Type, Tag string }
+	data := struct {
+		Name, DBName, IDTag string
+		Fields              []field
+	}{Name: e.Name, DBName: e.DBName, IDTag: naming.Snake(e.Name) + "_id"}
+	seen := map[string]bool{"ID": true, e.Name + "ID": true, "CreatedAt": true, "UpdatedAt": true}
+	for _, f := range e.Fields {
+		if seen[f.Name] {
+			return nil, fmt.Errorf("duplicate field %s", f.Name)
+		}
+		seen[f.Name] = true
+		data.Fields = append(data.Fields, field{Name: f.Name, Type: f.Type, Tag: tag(f)})
+	}
+
+	var decl bytes.Buffer
+	if err := declTemplate.Execute(&decl, data); err != nil {
+		return nil, err
+	}
+
+	var src []byte
+	if existing == nil {
+		src = []byte("package " + e.Package + "\n\nimport \"time\"\n")
+	} else {
+		var err error
+		if src, err = withTimeImport(existing, e.Name); err != nil {
+			return nil, err
+		}
+	}
+	src = append(bytes.TrimRight(src, "\n"), '\n')
+	src = append(src, decl.Bytes()...)
+	return format.Source(src)
+}
+
+// tag returns the struct tag of a scaffolded field.
+func tag(f Field) string {
+	name := naming.Snake(f.Name)
+	parts := []string{"json:" + strconv.Quote(name), "bson:" + strconv.Quote(name)}
+	if len(f.Validate) > 0 {
+		parts = append(parts, "validate:"+strconv.Quote(strings.Join(f.Validate, ",")))
+	}
+	if f.Index != "" {
+		parts = append(parts, "index:"+strconv.Quote(f.Index))
+	}
+	return strings.Join(parts, " ")
+}
+
+// withTimeImport checks that src does not declare name yet and adds an
+// import of "time" when it is missing.
+func withTimeImport(src []byte, name string) ([]byte, error) {
+	fset := token.NewFileSet()
+	f, err := goparser.ParseFile(fset, "data.go", src, 0)
+	if err != nil {
+		return nil, err
+	}
+	for _, d := range f.Decls {
+		gd, ok := d.(*ast.GenDecl)
+		if !ok || gd.Tok != token.TYPE {
+			continue
+		}
+		for _, spec := range gd.Specs {
+			if spec.(*ast.TypeSpec).Name.Name == name {
+				return nil, fmt.Errorf("%s is already declared", name)
+			}
+		}
+	}
+	for _, imp := range f.Imports {
+		if imp.Path.Value == `"time"` && (imp.Name == nil || imp.Name.Name == "time") {
+			return src, nil
+		}
+	}
+	var lastImport ast.Decl
+	for _, d := range f.Decls {
+		if gd, ok := d.(*ast.GenDecl); ok && gd.Tok == token.IMPORT {
+			lastImport = gd
+		}
+	}
+	at := f.Name.End()
+	if lastImport != nil {
+		at = lastImport.End()
+	}
+	offset := fset.Position(at).Offset
+	out := append([]byte(nil), src[:offset]...)
+	out = append(out, "\n\nimport \"time\"\n"...)
+	return append(out, src[offset:]...), nil
+}
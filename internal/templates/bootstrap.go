@@ -0,0 +1,526 @@
+package templates
+
+// Support files written by "dashgen init". The main.go examples differ per
+// framework; the other files are shared.
+
+var SDKUtils = `package utils
+
+// GetPointer returns a pointer to a copy of v, for optional option fields
+// such as the index options of the generated init.go files.
+func GetPointer[T any](v T) *T {
+	return &v
+}
+`
+
+// HTTPCommon holds the response and query types of the frameworks other
+// than sdk, which uses go-common/common.
+var HTTPCommon = `// Package common holds the response and query types of the generated
+// actions, handlers and client.
+package common
+
+// Status is the outcome of a request. The generated zz_routes.go maps it
+// to an HTTP status code.
+type Status string
+
+// APIStatus lists the statuses of a response.
+var APIStatus = struct {
+	Ok           Status
+	Invalid      Status
+	Unauthorized Status
+	Forbidden    Status
+	NotFound     Status
+	Existed      Status
+	Error        Status
+}{
+	Ok:           "OK",
+	Invalid:      "INVALID",
+	Unauthorized: "UNAUTHORIZED",
+	Forbidden:    "FORBIDDEN",
+	NotFound:     "NOT_FOUND",
+	Existed:      "EXISTED",
+	Error:        "ERROR",
+}
+
+// APIResponse is the reply of an endpoint. Data holds the entities it
+// returns and Total, for lists, the number of entities matching the filter.
+type APIResponse[T any] struct {
+	Status    Status ` + "`json:\"status\"`" + `
+	Message   string ` + "`json:\"message,omitempty\"`" + `
+	ErrorCode string ` + "`json:\"errorCode,omitempty\"`" + `
+	Data      []T    ` + "`json:\"data,omitempty\"`" + `
+	Total     int64  ` + "`json:\"total,omitempty\"`" + `
+}
+
+// GetStatus returns the status of the response.
+func (r *APIResponse[T]) GetStatus() Status { return r.Status }
+
+// GetMessage returns the message of the response.
+func (r *APIResponse[T]) GetMessage() string { return r.Message }
+
+// GetErrorCode returns the error code of the response.
+func (r *APIResponse[T]) GetErrorCode() string { return r.ErrorCode }
+
+// NewErrorResponse returns a failed response with status, an error code
+// and a message.
+func NewErrorResponse(status Status, errorCode, message string) *APIResponse[any] {
+	return &APIResponse[any]{Status: status, ErrorCode: errorCode, Message: message}
+}
+
+// FromError returns the failed response of err.
+func FromError(err error) *APIResponse[any] {
+	return &APIResponse[any]{Status: APIStatus.Error, Message: err.Error()}
+}
+
+// Query is the body of a list endpoint. Filter is read by the
+// Parse<Entity>Filter function of the entity T, Sort maps field names to
+// 1 (ascending) or -1 (descending).
+type Query[T any] struct {
+	Filter any            ` + "`json:\"filter,omitempty\"`" + `
+	Offset int64          ` + "`json:\"offset,omitempty\"`" + `
+	Limit  int64          ` + "`json:\"limit,omitempty\"`" + `
+	Sort   map[string]int ` + "`json:\"sort,omitempty\"`" + `
+}
+`
+
+// HTTPMongoCollection is the collection type of the MongoDB model layer
+// with the frameworks other than sdk, which uses go-mongodb/collection.
+var HTTPMongoCollection = `// Package collection stores the entities of the generated MongoDB model
+// layer. Documents are matched by example: the non-zero fields of a filter
+// entity select the documents.
+package collection
+
+import (
+	"context"
+	"reflect"
+	"sort"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Collection is a collection that is bound to its database later.
+type Collection interface {
+	SetDatabase(database *mongo.Database)
+}
+
+// MongoDBGenericCollection stores the entities of type T in a collection.
+type MongoDBGenericCollection[T any] struct {
+	name       string
+	collection *mongo.Collection
+}
+
+// NewMongoDBGenericCollection returns the collection name of T, a
+// *MongoDBGenericCollection[T]. Call SetDatabase before using it.
+func NewMongoDBGenericCollection[T any](name string) Collection {
+	return &MongoDBGenericCollection[T]{name: name}
+}
+
+// SetDatabase binds the collection to database.
+func (c *MongoDBGenericCollection[T]) SetDatabase(database *mongo.Database) {
+	c.collection = database.Collection(c.name)
+}
+
+// InsertOne stores data and returns the stored document. An empty _id is
+// replaced by a new object ID.
+func (c *MongoDBGenericCollection[T]) InsertOne(data *T) (*T, error) {
+	doc, err := document(data)
+	if err != nil {
+		return nil, err
+	}
+	for i, e := range doc {
+		if e.Key == "_id" && (e.Value == nil || e.Value == "") {
+			doc[i].Value = primitive.NewObjectID().Hex()
+		}
+	}
+	res, err := c.collection.InsertOne(context.Background(), doc)
+	if err != nil {
+		return nil, err
+	}
+	return c.decodeOne(c.collection.FindOne(context.Background(), bson.D{bson.E{Key: "_id", Value: res.InsertedID}}))
+}
+
+// FindOne returns the first document matching filter, or
+// mongo.ErrNoDocuments.
+func (c *MongoDBGenericCollection[T]) FindOne(filter T) (*T, error) {
+	return c.decodeOne(c.collection.FindOne(context.Background(), example(filter)))
+}
+
+// Find returns the documents matching query, a query document, skipping
+// offset and returning at most limit (0 for all). sort maps field names to
+// 1 or -1; the fields are sorted by name, as a map has no order.
+func (c *MongoDBGenericCollection[T]) Find(query any, offset, limit int64, sort map[string]int) ([]*T, error) {
+	opts := options.Find().SetSkip(offset).SetSort(sortDocument(sort))
+	if limit > 0 {
+		opts.SetLimit(limit)
+	}
+	cur, err := c.collection.Find(context.Background(), query, opts)
+	if err != nil {
+		return nil, err
+	}
+	var items []*T
+	if err := cur.All(context.Background(), &items); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+// Count returns the number of documents matching query.
+func (c *MongoDBGenericCollection[T]) Count(query any) (int64, error) {
+	return c.collection.CountDocuments(context.Background(), query)
+}
+
+// UpdateOne sets the non-zero fields of data on the first document matching
+// filter and returns the updated document.
+func (c *MongoDBGenericCollection[T]) UpdateOne(filter T, data *T) (*T, error) {
+	update := example(*data)
+	for i, e := range update {
+		if e.Key == "_id" {
+			update = append(update[:i], update[i+1:]...)
+			break
+		}
+	}
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+	return c.decodeOne(c.collection.FindOneAndUpdate(context.Background(), example(filter), bson.D{bson.E{Key: "$set", Value: update}}, opts))
+}
+
+// DeleteOne deletes the first document matching filter.
+func (c *MongoDBGenericCollection[T]) DeleteOne(filter T) error {
+	_, err := c.collection.DeleteOne(context.Background(), example(filter))
+	return err
+}
+
+// CreateIndex creates an index on keys, a key document, with opts (may be
+// nil).
+func (c *MongoDBGenericCollection[T]) CreateIndex(keys any, opts *options.IndexOptions) error {
+	_, err := c.collection.Indexes().CreateOne(context.Background(), mongo.IndexModel{Keys: keys, Options: opts})
+	return err
+}
+
+func (c *MongoDBGenericCollection[T]) decodeOne(res *mongo.SingleResult) (*T, error) {
+	var out T
+	if err := res.Decode(&out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// document encodes v as an ordered document.
+func document(v any) (bson.D, error) {
+	raw, err := bson.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var doc bson.D
+	err = bson.Unmarshal(raw, &doc)
+	return doc, err
+}
+
+// example returns the non-zero fields of v, a struct, under their bson
+// names.
+func example(v any) bson.D {
+	rv := reflect.Indirect(reflect.ValueOf(v))
+	doc := bson.D{}
+	for i := 0; i < rv.NumField(); i++ {
+		f := rv.Type().Field(i)
+		if !f.IsExported() || rv.Field(i).IsZero() {
+			continue
+		}
+		name, _, _ := strings.Cut(f.Tag.Get("bson"), ",")
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = strings.ToLower(f.Name)
+		}
+		doc = append(doc, bson.E{Key: name, Value: rv.Field(i).Interface()})
+	}
+	return doc
+}
+
+// sortDocument returns fields as a sort document, ordered by field name.
+func sortDocument(fields map[string]int) bson.D {
+	names := make([]string, 0, len(fields))
+	for name := range fields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	doc := make(bson.D, 0, len(names))
+	for _, name := range names {
+		doc = append(doc, bson.E{Key: name, Value: fields[name]})
+	}
+	return doc
+}
+`
+
+var SDKClient = `package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// BackendServiceClient calls the API of a service generated by dashgen.
+// The generated client/<entity>.go files add one method per endpoint.
+type BackendServiceClient struct {
+	BaseURL    string
+	HTTPClient *http.Client // nil uses http.DefaultClient
+}
+
+// NewBackendServiceClient returns a client for the service at baseURL,
+// e.g. "http://localhost:8080".
+func NewBackendServiceClient(baseURL string) *BackendServiceClient {
+	return &BackendServiceClient{BaseURL: baseURL}
+}
+
+// makeRequest sends body as JSON, with params in the query string, and
+// decodes the JSON reply into response. The request is bound to ctx.
+func (c *BackendServiceClient) makeRequest(ctx context.Context, method, path string, params map[string]string, body, response any) error {
+	u, err := url.Parse(strings.TrimRight(c.BaseURL, "/") + path)
+	if err != nil {
+		return err
+	}
+	q := u.Query()
+	for k, v := range params {
+		q.Set(k, v)
+	}
+	u.RawQuery = q.Encode()
+
+	var payload io.Reader
+	if body != nil {
+		b, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		payload = bytes.NewReader(b)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, u.String(), payload)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	hc := c.HTTPClient
+	if hc == nil {
+		hc = http.DefaultClient
+	}
+	resp, err := hc.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if err := json.NewDecoder(resp.Body).Decode(response); err != nil {
+		return fmt.Errorf("%s %s: %s: %w", method, path, resp.Status, err)
+	}
+	return nil
+}
+`
+
+var SDKMainExample = `// Wiring example written by "dashgen init". Rename it to main.go and
+// adapt it; dashgen never touches main.go.
+package main
+
+import (
+	"context"
+{{- if ne .Backend "mongo"}}
+	"database/sql"
+{{- end}}
+	"log"
+{{- if eq .Backend "postgres"}}
+	"os"
+
+	_ "github.com/jackc/pgx/v5/stdlib"
+{{- else if eq .Backend "sqlite"}}
+
+	_ "modernc.org/sqlite"
+{{- else}}
+
+	"gitlab.silvertiger.tech/go-sdk/go-mongodb/client"
+	"go.mongodb.org/mongo-driver/mongo"
+{{- end}}
+
+	"{{.Module}}/model"
+)
+
+func main() {
+{{- if eq .Backend "postgres"}}
+	// Setup PostgreSQL connection
+	db, err := sql.Open("pgx", os.Getenv("DATABASE_URL"))
+	if err != nil {
+		log.Fatal(err)
+	}
+{{- else if eq .Backend "sqlite"}}
+	// Open the SQLite database, created on first use
+	db, err := sql.Open("sqlite", "{{.Name}}.db")
+	if err != nil {
+		log.Fatal(err)
+	}
+{{- else}}
+	// Setup MongoDB connection
+	mongoClient := client.NewMongoClient("{{.Name}}", config, onDBConnected)
+	err := mongoClient.Connect()
+	if err != nil {
+		log.Fatal(err)
+	}
+{{- end}}
+{{- if and (ne .Backend "mongo") (eq .Style "di")}}
+	// Create the repositories of all entities
+	repos, err := model.NewRepositories(context.Background(), db)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	// Register the generated handlers (import "{{.Module}}/internal/api"):
+	// api.NewHandlers(repos).RegisterRoutes(func(method, path string, h api.Handler) error {
+	// 	return server.SetHandler(method, path, h)
+	// })
+	_ = repos
+}
+{{- else}}
+{{- if ne .Backend "mongo"}}
+	// Create the tables of all entities
+	if err := model.InitAll(context.Background(), db); err != nil {
+		log.Fatal(err)
+	}
+{{- end}}
+
+{{- if eq .Style "di"}}
+}
+{{- else}}
+
+	// Register the generated handlers (import "{{.Module}}/internal/api"):
+	// api.RegisterRoutes(func(method, path string, h api.Handler) error {
+	// 	return server.SetHandler(method, path, h)
+	// })
+}
+{{- end}}
+{{- end}}
+{{- if eq .Backend "mongo"}}
+
+func onDBConnected(database *mongo.Database) error {
+{{- if eq .Style "di"}}
+	// Create the repositories of all entities
+	repos, err := model.NewRepositories(context.Background(), database)
+	if err != nil {
+		return err
+	}
+
+	// Register the generated handlers (import "{{.Module}}/internal/api"):
+	// api.NewHandlers(repos).RegisterRoutes(func(method, path string, h api.Handler) error {
+	// 	return server.SetHandler(method, path, h)
+	// })
+	_ = repos
+	return nil
+{{- else}}
+	// Initialize the collections of all entities
+	return model.InitAll(context.Background(), database)
+{{- end}}
+}
+{{- end}}
+`
+
+var HTTPMainExample = `// Wiring example written by "dashgen init". Rename it to main.go and
+// adapt it; dashgen never touches main.go.
+package main
+
+import (
+	"context"
+{{- if ne .Backend "mongo"}}
+	"database/sql"
+{{- end}}
+	"log"
+	"net/http"
+{{- if ne .Backend "sqlite"}}
+	"os"
+{{- end}}
+{{/* third-party imports, in gofmt order */}}
+{{- if eq .Framework "gin"}}
+	"github.com/gin-gonic/gin"
+{{- end}}
+{{- if eq .Framework "chi"}}
+	"github.com/go-chi/chi/v5"
+{{- end}}
+{{- if eq .Backend "postgres"}}
+	_ "github.com/jackc/pgx/v5/stdlib"
+{{- end}}
+{{- if eq .Framework "echo"}}
+	"github.com/labstack/echo/v4"
+{{- end}}
+{{- if eq .Backend "mongo"}}
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+{{- end}}
+{{- if eq .Backend "sqlite"}}
+	_ "modernc.org/sqlite"
+{{- end}}
+
+	"{{.Module}}/internal/api"
+	"{{.Module}}/model"
+)
+
+func main() {
+	ctx := context.Background()
+{{if eq .Backend "postgres"}}
+	// Setup PostgreSQL connection
+	db, err := sql.Open("pgx", os.Getenv("DATABASE_URL"))
+	if err != nil {
+		log.Fatal(err)
+	}
+{{- else if eq .Backend "sqlite"}}
+	// Open the SQLite database, created on first use
+	db, err := sql.Open("sqlite", "{{.Name}}.db")
+	if err != nil {
+		log.Fatal(err)
+	}
+{{- else}}
+	// Setup MongoDB connection
+	mongoClient, err := mongo.Connect(ctx, options.Client().ApplyURI(os.Getenv("MONGODB_URI")))
+	if err != nil {
+		log.Fatal(err)
+	}
+{{- end}}
+{{- $db := "db"}}
+{{- if eq .Backend "mongo"}}{{$db = printf "mongoClient.Database(%q)" .Name}}{{end}}
+{{- if eq .Style "di"}}
+	// Create the repositories of all entities
+	repos, err := model.NewRepositories(ctx, {{$db}})
+	if err != nil {
+		log.Fatal(err)
+	}
+{{- else}}
+{{- if eq .Backend "mongo"}}
+	// Initialize the collections of all entities
+{{- else}}
+	// Create the tables of all entities
+{{- end}}
+	if err := model.InitAll(ctx, {{$db}}); err != nil {
+		log.Fatal(err)
+	}
+{{- end}}
+
+	// Register the generated handlers
+{{- if eq .Framework "chi"}}
+	router := chi.NewRouter()
+{{- else if eq .Framework "gin"}}
+	router := gin.Default()
+{{- else if eq .Framework "echo"}}
+	router := echo.New()
+{{- else}}
+	router := http.NewServeMux()
+{{- end}}
+{{- if eq .Style "di"}}
+	api.NewHandlers(repos).RegisterRoutes(router)
+{{- else}}
+	api.RegisterRoutes(router)
+{{- end}}
+	log.Fatal(http.ListenAndServe(":8080", router))
+}
+`
@@ -0,0 +1,114 @@
+package templates
+
+// Cursor pagination of every entity, generated next to the repository of
+// every backend. Pages follow the sort order of the cursor index; cursors
+// are opaque tokens holding the values of that order at the page boundary.
+
+var Cursor = `package {{.Package}}
+{{- $cursor := cursorFields .Fields .Indexes (printf "%sID" .Entity)}}
+{{- $type := printf "%sCursor" .EntityLower}}
+{{- $time := false}}{{range $cursor}}{{if eq .Kind "time"}}{{$time = true}}{{end}}{{end}}
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+{{- if $time}}
+	"time"
+{{- end}}
+)
+
+// ErrInvalidCursor is returned for a cursor that is malformed or was
+// issued for another cursor index.
+var ErrInvalidCursor = errors.New("invalid {{.EntityLower}} cursor")
+
+// {{.Entity}}Page is a page of {{.EntityLower}}s in the order of the cursor index:
+// {{range $i, $c := $cursor}}{{if $i}}, {{end}}{{.BSONName}}{{if .Desc}} descending{{end}}{{end}}.
+type {{.Entity}}Page struct {
+	Items []*{{.Entity}}
+	Next  string // cursor of the next page, "" on the last page
+	Prev  string // cursor of the previous page, "" on the first page
+}
+
+// {{.Entity}}PageQuery asks for the page of {{.EntityLower}}s matching Filter that
+// Cursor points to.
+type {{.Entity}}PageQuery struct {
+	Filter *{{.Entity}}Filter ` + "`json:\"filter,omitempty\"`" + `
+	Cursor string ` + "`json:\"cursor,omitempty\"`" + ` // "" for the first page
+	Limit  int64 ` + "`json:\"limit,omitempty\"`" + `
+	Count  bool ` + "`json:\"count,omitempty\"`" + ` // also count the matching {{.EntityLower}}s
+}
+
+// {{.EntityLower}}Keyset identifies the cursor index in cursors.
+const {{.EntityLower}}Keyset = "{{range $i, $c := $cursor}}{{if $i}},{{end}}{{.BSONName}}:{{if .Desc}}-1{{else}}1{{end}}{{end}}"
+
+// {{$type}} is the decoded form of a cursor: the cursor index values
+// of the {{.EntityLower}} a page starts after, or ends before when Back is set.
+type {{$type}} struct {
+	Keyset string ` + "`json:\"$keyset\"`" + `
+	Back   bool ` + "`json:\"$back,omitempty\"`" + `
+{{- range $cursor}}
+	{{.Field}} {{.Type}} ` + "`json:\"{{.JSONName}}\"`" + `
+{{- end}}
+}
+
+// {{$type}}Of returns the cursor pointing at e.
+func {{$type}}Of(e *{{.Entity}}, back bool) *{{$type}} {
+	return &{{$type}}{Keyset: {{.EntityLower}}Keyset, Back: back{{range $cursor}}, {{.Field}}: e.{{.Field}}{{end}}}
+}
+
+// String encodes the cursor as an opaque URL-safe token.
+func (c *{{$type}}) String() string {
+	b, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+// values returns the cursor index values in index order.
+func (c *{{$type}}) values() []any {
+	return []any{ {{- range $i, $c := $cursor}}{{if $i}}, {{end}}c.{{.Field}}{{end}}}
+}
+
+// parse{{.Entity}}Cursor decodes a cursor. The empty cursor is the first
+// page and decodes to nil.
+func parse{{.Entity}}Cursor(s string) (*{{$type}}, error) {
+	if s == "" {
+		return nil, nil
+	}
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	var c {{$type}}
+	if err := json.Unmarshal(b, &c); err != nil || c.Keyset != {{.EntityLower}}Keyset {
+		return nil, ErrInvalidCursor
+	}
+	return &c, nil
+}
+
+// new{{.Entity}}Page builds the page from the {{.EntityLower}}s fetched from the
+// position of c in the direction of the page: backwards, in reverse order,
+// for a Back cursor. One item more than limit tells that more follow.
+func new{{.Entity}}Page(items []*{{.Entity}}, c *{{$type}}, limit int64) *{{.Entity}}Page {
+	more := limit > 0 && int64(len(items)) > limit
+	if more {
+		items = items[:limit]
+	}
+	back := c != nil && c.Back
+	if back {
+		for i, j := 0, len(items)-1; i < j; i, j = i+1, j-1 {
+			items[i], items[j] = items[j], items[i]
+		}
+	}
+	page := &{{.Entity}}Page{Items: items}
+	if len(items) == 0 {
+		return page
+	}
+	if back && more || !back && c != nil {
+		page.Prev = {{$type}}Of(items[0], true).String()
+	}
+	if !back && more || back {
+		page.Next = {{$type}}Of(items[len(items)-1], false).String()
+	}
+	return page
+}
+`
@@ -0,0 +1,310 @@
+package templates
+
+// Typed filter of every entity, generated next to the repository of every
+// backend. The filter only holds conditions; each repository translates
+// them into its own query form.
+
+var Filter = `package {{.Package}}
+{{- $filter := printf "%sFilter" .Entity}}
+{{- $fields := filterFields .Fields}}
+{{- $time := false}}{{range $fields}}{{if eq .Kind "time"}}{{$time = true}}{{end}}{{end}}
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+{{- if $time}}
+	"time"
+{{- end}}
+)
+
+// {{$filter}} selects {{.EntityLower}}s in List and Count. Its methods add
+// conditions that must all hold and return the filter, so that calls
+// chain. A nil *{{$filter}} selects every {{.EntityLower}}.
+//
+// In JSON a filter maps field names to operators, e.g.
+// {"age": {"$gte": 18}}; Parse{{$filter}} reads that form back.
+type {{$filter}} struct {
+	conds []filterCondition
+}
+
+// filterCondition is a condition on the field with the JSON name field.
+// The value of $in and $nin is a []any.
+type filterCondition struct {
+	field string
+	op    string
+	value any
+}
+
+// New{{$filter}} returns a filter selecting every {{.EntityLower}}.
+func New{{$filter}}() *{{$filter}} {
+	return &{{$filter}}{}
+}
+
+func (f *{{$filter}}) add(field, op string, value any) *{{$filter}} {
+	f.conds = append(f.conds, filterCondition{field: field, op: op, value: value})
+	return f
+}
+{{range $fields}}
+{{- if eq .Kind "bool"}}
+// {{.Field}}Is selects the {{$.EntityLower}}s whose {{.Field}} is v.
+func (f *{{$filter}}) {{.Field}}Is(v bool) *{{$filter}} {
+	return f.add("{{.JSONName}}", "$eq", v)
+}
+{{- else if eq .Kind "time"}}
+// {{.Field}}Before selects the {{$.EntityLower}}s whose {{.Field}} is before t.
+func (f *{{$filter}}) {{.Field}}Before(t time.Time) *{{$filter}} {
+	return f.add("{{.JSONName}}", "$lt", t)
+}
+
+// {{.Field}}After selects the {{$.EntityLower}}s whose {{.Field}} is after t.
+func (f *{{$filter}}) {{.Field}}After(t time.Time) *{{$filter}} {
+	return f.add("{{.JSONName}}", "$gt", t)
+}
+
+// {{.Field}}Between selects the {{$.EntityLower}}s whose {{.Field}} is between
+// from and to, both included.
+func (f *{{$filter}}) {{.Field}}Between(from, to time.Time) *{{$filter}} {
+	return f.add("{{.JSONName}}", "$gte", from).add("{{.JSONName}}", "$lte", to)
+}
+{{- else}}
+// {{.Field}}Eq selects the {{$.EntityLower}}s whose {{.Field}} is v.
+func (f *{{$filter}}) {{.Field}}Eq(v {{.Type}}) *{{$filter}} {
+	return f.add("{{.JSONName}}", "$eq", v)
+}
+
+// {{.Field}}Ne selects the {{$.EntityLower}}s whose {{.Field}} is not v.
+func (f *{{$filter}}) {{.Field}}Ne(v {{.Type}}) *{{$filter}} {
+	return f.add("{{.JSONName}}", "$ne", v)
+}
+
+// {{.Field}}In selects the {{$.EntityLower}}s whose {{.Field}} is one of vs.
+func (f *{{$filter}}) {{.Field}}In(vs ...{{.Type}}) *{{$filter}} {
+	return f.add("{{.JSONName}}", "$in", filterList(vs))
+}
+
+// {{.Field}}NotIn selects the {{$.EntityLower}}s whose {{.Field}} is none of vs.
+func (f *{{$filter}}) {{.Field}}NotIn(vs ...{{.Type}}) *{{$filter}} {
+	return f.add("{{.JSONName}}", "$nin", filterList(vs))
+}
+{{- if eq .Kind "string"}}
+
+// {{.Field}}Contains selects the {{$.EntityLower}}s whose {{.Field}} contains s.
+func (f *{{$filter}}) {{.Field}}Contains(s string) *{{$filter}} {
+	return f.add("{{.JSONName}}", "$contains", s)
+}
+{{- else}}
+
+// {{.Field}}Gt selects the {{$.EntityLower}}s whose {{.Field}} is greater than v.
+func (f *{{$filter}}) {{.Field}}Gt(v {{.Type}}) *{{$filter}} {
+	return f.add("{{.JSONName}}", "$gt", v)
+}
+
+// {{.Field}}Gte selects the {{$.EntityLower}}s whose {{.Field}} is at least v.
+func (f *{{$filter}}) {{.Field}}Gte(v {{.Type}}) *{{$filter}} {
+	return f.add("{{.JSONName}}", "$gte", v)
+}
+
+// {{.Field}}Lt selects the {{$.EntityLower}}s whose {{.Field}} is less than v.
+func (f *{{$filter}}) {{.Field}}Lt(v {{.Type}}) *{{$filter}} {
+	return f.add("{{.JSONName}}", "$lt", v)
+}
+
+// {{.Field}}Lte selects the {{$.EntityLower}}s whose {{.Field}} is at most v.
+func (f *{{$filter}}) {{.Field}}Lte(v {{.Type}}) *{{$filter}} {
+	return f.add("{{.JSONName}}", "$lte", v)
+}
+
+// {{.Field}}Between selects the {{$.EntityLower}}s whose {{.Field}} is between
+// lo and hi, both included.
+func (f *{{$filter}}) {{.Field}}Between(lo, hi {{.Type}}) *{{$filter}} {
+	return f.add("{{.JSONName}}", "$gte", lo).add("{{.JSONName}}", "$lte", hi)
+}
+{{- end}}
+{{- end}}
+{{end}}
+// {{.EntityLower}}FilterOps are the operators the JSON form of a filter
+// accepts on every field.
+var {{.EntityLower}}FilterOps = map[string]string{
+{{- range $fields}}
+	"{{.JSONName}}": "{{.Ops}}",
+{{- end}}
+}
+
+// Parse{{$filter}} converts the filter of a query. filter is nil, a
+// *{{$filter}}, a {{.Entity}} whose non-zero fields must all match, or the
+// JSON form of a filter, e.g. decoded from a request body. Unknown fields
+// and operators are errors. A {{.Entity}} matches its fields with $eq, so
+// its time fields, which only support ranges, are ignored.
+func Parse{{$filter}}(filter any) (*{{$filter}}, error) {
+	entity := false
+	switch v := filter.(type) {
+	case nil:
+		return nil, nil
+	case *{{$filter}}:
+		return v, nil
+	case {{$filter}}:
+		return &v, nil
+	case {{.Entity}}, *{{.Entity}}:
+		entity = true
+	}
+	b, err := json.Marshal(filter)
+	if err != nil {
+		return nil, fmt.Errorf("{{.EntityLower}} filter: %w", err)
+	}
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(b, &fields); err != nil {
+		return nil, fmt.Errorf("{{.EntityLower}} filter: %w", err)
+	}
+	if entity {
+		for name, raw := range fields {
+			ops, known := {{.EntityLower}}FilterOps[name]
+			if filterZero(raw) || known && !strings.Contains(" "+ops+" ", " $eq ") {
+				delete(fields, name)
+			}
+		}
+	}
+	f := New{{$filter}}()
+	if err := f.decode(fields); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+// MarshalJSON encodes the filter as field names mapped to operators.
+// Conditions repeating an operator on a field are listed under "$and".
+func (f *{{$filter}}) MarshalJSON() ([]byte, error) {
+	fields := map[string]map[string]any{}
+	for _, c := range f.conds {
+		ops := fields[c.field]
+		if ops == nil {
+			ops = map[string]any{}
+			fields[c.field] = ops
+		}
+		if _, ok := ops[c.op]; ok {
+			all := make([]map[string]map[string]any, len(f.conds))
+			for i, c := range f.conds {
+				all[i] = map[string]map[string]any{c.field: {c.op: c.value}}
+			}
+			return json.Marshal(map[string]any{"$and": all})
+		}
+		ops[c.op] = c.value
+	}
+	return json.Marshal(fields)
+}
+
+// UnmarshalJSON decodes the JSON form of a filter.
+func (f *{{$filter}}) UnmarshalJSON(b []byte) error {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(b, &fields); err != nil {
+		return fmt.Errorf("{{.EntityLower}} filter: %w", err)
+	}
+	f.conds = nil
+	return f.decode(fields)
+}
+
+// decode adds the conditions of the JSON form of a filter: field names
+// mapped to a value or to operators, and "$and" holding a list of such
+// objects. Names are read in sorted order.
+func (f *{{$filter}}) decode(fields map[string]json.RawMessage) error {
+	for _, name := range filterKeys(fields) {
+		raw := fields[name]
+		if name == "$and" {
+			var all []map[string]json.RawMessage
+			if err := json.Unmarshal(raw, &all); err != nil {
+				return fmt.Errorf("filter $and: %w", err)
+			}
+			for _, sub := range all {
+				if err := f.decode(sub); err != nil {
+					return err
+				}
+			}
+			continue
+		}
+		ops := map[string]json.RawMessage{"$eq": raw}
+		var m map[string]json.RawMessage
+		if json.Unmarshal(raw, &m) == nil && len(m) > 0 && filterOperators(m) {
+			ops = m
+		}
+		for _, op := range filterKeys(ops) {
+			if err := f.set(name, op, ops[op]); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// set adds the condition op on field, its value decoded from raw.
+func (f *{{$filter}}) set(field, op string, raw json.RawMessage) error {
+	ops, ok := {{.EntityLower}}FilterOps[field]
+	if !ok {
+		return fmt.Errorf("unknown filter field %q", field)
+	}
+	if !strings.Contains(" "+ops+" ", " "+op+" ") {
+		return fmt.Errorf("filter operator %s is not supported on %s", op, field)
+	}
+	switch field {
+{{- range $fields}}
+	case "{{.JSONName}}":
+		return filterDecode[{{.Type}}](f, field, op, raw)
+{{- end}}
+	}
+	return nil
+}
+
+// filterDecode adds the condition op on field with a value of type T.
+func filterDecode[T any](f *{{$filter}}, field, op string, raw json.RawMessage) error {
+	if op == "$in" || op == "$nin" {
+		var vs []T
+		if err := json.Unmarshal(raw, &vs); err != nil {
+			return fmt.Errorf("filter %s %s: %w", field, op, err)
+		}
+		f.add(field, op, filterList(vs))
+		return nil
+	}
+	var v T
+	if err := json.Unmarshal(raw, &v); err != nil {
+		return fmt.Errorf("filter %s %s: %w", field, op, err)
+	}
+	f.add(field, op, v)
+	return nil
+}
+
+func filterList[T any](vs []T) []any {
+	list := make([]any, len(vs))
+	for i, v := range vs {
+		list[i] = v
+	}
+	return list
+}
+
+func filterKeys(m map[string]json.RawMessage) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// filterOperators reports whether all keys of m are operators.
+func filterOperators(m map[string]json.RawMessage) bool {
+	for k := range m {
+		if !strings.HasPrefix(k, "$") {
+			return false
+		}
+	}
+	return true
+}
+
+// filterZero reports whether raw is the JSON form of a zero value.
+func filterZero(raw json.RawMessage) bool {
+	switch string(raw) {
+	case "null", ` + "`\"\"`" + `, "0", "false", "[]", "{}", ` + "`\"0001-01-01T00:00:00Z\"`" + `:
+		return true
+	}
+	return false
+}
+`
@@ -0,0 +1,243 @@
+package templates
+
+// Route registration files of the HTTP frameworks other than sdk. The
+// generated handlers only depend on the Request and Responder interfaces
+// declared here; each variant adapts them to its framework.
+
+// handlerTypes are the interfaces the generated handlers take. They are
+// written to the helpers file, which every partial run renders too.
+const handlerTypes = `
+// Request is what the generated handlers read from an HTTP request.
+type Request interface {
+	// Context is the context of the request, passed down to the action
+	// and repository layers.
+	Context() context.Context
+	// ParseBody decodes the JSON request body into v.
+	ParseBody(v any) error
+	// GetParam returns the query parameter name, or "".
+	GetParam(name string) string
+}
+
+// Responder writes the reply of a generated handler.
+type Responder interface {
+	Respond(response any) error
+}
+`
+
+// httpRoutes is shared by every variant: the route table and the mapping
+// of response statuses to HTTP status codes.
+const httpRoutes = `
+// Handler is the signature of the generated API handlers.
+type Handler = func(req Request, res Responder) error
+
+// Route is a generated endpoint.
+type Route struct {
+	Method  string
+	Path    string
+	Handler Handler
+}
+
+` + routeTable + `
+// encode returns the JSON body of response and the HTTP status code
+// matching its "status" field.
+func encode(response any) (int, []byte, error) {
+	body, err := json.Marshal(response)
+	if err != nil {
+		return 0, nil, err
+	}
+	var reply struct {
+		Status string ` + "`json:\"status\"`" + `
+	}
+	_ = json.Unmarshal(body, &reply)
+	return httpStatus(reply.Status), body, nil
+}
+
+// httpStatus maps a response status to an HTTP status code.
+func httpStatus(status string) int {
+	switch strings.ToUpper(status) {
+	case "", "OK":
+		return http.StatusOK
+	case "INVALID":
+		return http.StatusBadRequest
+	case "UNAUTHORIZED":
+		return http.StatusUnauthorized
+	case "FORBIDDEN":
+		return http.StatusForbidden
+	case "NOT_FOUND":
+		return http.StatusNotFound
+	case "EXISTED", "CONFLICT":
+		return http.StatusConflict
+	}
+	return http.StatusInternalServerError
+}
+`
+
+// stdlibAdapters adapt net/http, used by the nethttp and chi variants.
+const stdlibAdapters = `
+type httpRequest struct{ r *http.Request }
+
+func (req httpRequest) Context() context.Context { return req.r.Context() }
+
+func (req httpRequest) ParseBody(v any) error { return json.NewDecoder(req.r.Body).Decode(v) }
+
+func (req httpRequest) GetParam(name string) string { return req.r.URL.Query().Get(name) }
+
+type httpResponder struct {
+	w       http.ResponseWriter
+	written bool
+}
+
+func (res *httpResponder) Respond(response any) error {
+	code, body, err := encode(response)
+	if err != nil {
+		return err
+	}
+	res.written = true
+	res.w.Header().Set("Content-Type", "application/json")
+	res.w.WriteHeader(code)
+	_, err = res.w.Write(body)
+	return err
+}
+
+// serveHTTP adapts a generated handler to net/http.
+func serveHTTP(h Handler) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		res := &httpResponder{w: w}
+		if err := h(httpRequest{r}, res); err != nil && !res.written {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+	}
+}
+`
+
+var RoutesNetHTTP = `package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+` + diImports + `
+)
+` + httpRoutes + stdlibAdapters + `
+// RegisterRoutes registers every route on mux with Go 1.22 method
+// patterns, e.g. "POST /v1/user".
+func {{if eq .Style "di"}}(h *Handlers) {{end}}RegisterRoutes(mux *http.ServeMux) {
+	for _, r := range {{if eq .Style "di"}}h.Routes(){{else}}Routes{{end}} {
+		mux.HandleFunc(r.Method+" "+r.Path, serveHTTP(r.Handler))
+	}
+}
+`
+
+var RoutesChi = `package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+` + diImports + `
+)
+` + httpRoutes + stdlibAdapters + `
+// RegisterRoutes registers every route on r. The QUERY method of the list
+// endpoints is registered with chi first.
+func {{if eq .Style "di"}}(h *Handlers) {{end}}RegisterRoutes(r chi.Router) {
+	chi.RegisterMethod("QUERY")
+	for _, route := range {{if eq .Style "di"}}h.Routes(){{else}}Routes{{end}} {
+		r.MethodFunc(route.Method, route.Path, serveHTTP(route.Handler))
+	}
+}
+`
+
+var RoutesGin = `package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+` + diImports + `
+)
+` + httpRoutes + `
+type ginRequest struct{ c *gin.Context }
+
+func (req ginRequest) Context() context.Context { return req.c.Request.Context() }
+
+func (req ginRequest) ParseBody(v any) error { return json.NewDecoder(req.c.Request.Body).Decode(v) }
+
+func (req ginRequest) GetParam(name string) string { return req.c.Query(name) }
+
+type ginResponder struct{ c *gin.Context }
+
+func (res ginResponder) Respond(response any) error {
+	code, body, err := encode(response)
+	if err != nil {
+		return err
+	}
+	res.c.Data(code, "application/json", body)
+	return nil
+}
+
+// RegisterRoutes registers every route on r, a *gin.Engine or a
+// *gin.RouterGroup.
+func {{if eq .Style "di"}}(h *Handlers) {{end}}RegisterRoutes(r gin.IRoutes) {
+	for _, route := range {{if eq .Style "di"}}h.Routes(){{else}}Routes{{end}} {
+		h := route.Handler
+		r.Handle(route.Method, route.Path, func(c *gin.Context) {
+			if err := h(ginRequest{c}, ginResponder{c}); err != nil && !c.Writer.Written() {
+				c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"message": err.Error()})
+			}
+		})
+	}
+}
+`
+
+var RoutesEcho = `package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+` + diImports + `
+)
+` + httpRoutes + `
+type echoRequest struct{ c echo.Context }
+
+func (req echoRequest) Context() context.Context { return req.c.Request().Context() }
+
+func (req echoRequest) ParseBody(v any) error { return json.NewDecoder(req.c.Request().Body).Decode(v) }
+
+func (req echoRequest) GetParam(name string) string { return req.c.QueryParam(name) }
+
+type echoResponder struct{ c echo.Context }
+
+func (res echoResponder) Respond(response any) error {
+	code, body, err := encode(response)
+	if err != nil {
+		return err
+	}
+	return res.c.JSONBlob(code, body)
+}
+
+// Router is implemented by *echo.Echo and *echo.Group.
+type Router interface {
+	Add(method, path string, handler echo.HandlerFunc, middleware ...echo.MiddlewareFunc) *echo.Route
+}
+
+// RegisterRoutes registers every route on r.
+func {{if eq .Style "di"}}(h *Handlers) {{end}}RegisterRoutes(r Router) {
+	for _, route := range {{if eq .Style "di"}}h.Routes(){{else}}Routes{{end}} {
+		h := route.Handler
+		r.Add(route.Method, route.Path, func(c echo.Context) error {
+			return h(echoRequest{c}, echoResponder{c})
+		})
+	}
+}
+`
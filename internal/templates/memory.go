@@ -0,0 +1,551 @@
+package templates
+
+// In-memory Repository, generated next to the repository of every
+// backend. It mirrors the MongoDB semantics: names in sorts are bson (or
+// JSON) names, missing fields compare as null, and deletes move documents
+// out of the live set.
+
+var MemoryRepository = `package {{.Package}}
+{{- $key := printf "%sID" .Entity}}
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Errors of the in-memory repository.
+var (
+	ErrNotFound  = errors.New("{{.EntityLower}} not found")
+	ErrDuplicate = errors.New("duplicate {{.EntityLower}}")
+)
+
+// memoryRepository implements the Repository interface in memory. It is
+// safe for concurrent use and holds shallow copies of the {{.EntityLower}}s
+// it is given.
+type memoryRepository struct {
+	mu      sync.RWMutex
+	live    []*{{.Entity}} // in insertion order
+	deleted []*{{.Entity}}
+}
+
+// NewMemoryRepository returns an empty in-memory Repository, for tests and
+// local runs{{if eq .Style "di"}}; pass it wherever a Repository is expected{{else}}; install it with SetRepository{{end}}. It enforces the unique
+// indexes of {{.Entity}}, soft deletes, and evaluates filters and sorts
+// like the MongoDB repository.
+func NewMemoryRepository() Repository {
+	return &memoryRepository{}
+}
+
+// {{.EntityLower}}MemoryFields are the stored fields of {{.Entity}}.
+var {{.EntityLower}}MemoryFields = []memoryField{
+{{- range memoryFields .Fields}}
+	{bson: "{{.BSONName}}", json: "{{.JSONName}}"{{if .OmitEmpty}}, omitEmpty: true{{end}}, get: func(e *{{$.Entity}}) any { return e.{{.Field}} }},
+{{- end}}
+}
+
+// {{.EntityLower}}UniqueIndexes are the unique indexes of {{.Entity}}.
+var {{.EntityLower}}UniqueIndexes = []memoryIndex{
+{{- range memoryIndexes .Fields .Indexes $key}}
+	{fields: []string{ {{- quoteAll .Fields | join ", "}}}
+	{{- if .Sparse}}, sparse: true{{end}}
+	{{- if .Partial}}, partial: map[string]any{ {{- range $i, $c := .Partial}}{{if $i}}, {{end}}"{{$c.Field}}": {{$c.Value}}{{end}}}{{end -}}
+	},
+{{- end}}
+}
+
+func (r *memoryRepository) Create(ctx context.Context, data *{{.Entity}}) (*{{.Entity}}, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	v := *data
+	if err := r.checkUnique(&v, nil); err != nil {
+		return nil, err
+	}
+	r.live = append(r.live, &v)
+	out := v
+	return &out, nil
+}
+
+func (r *memoryRepository) GetBy{{.Entity}}ID(ctx context.Context, {{.EntityLower}}ID string) (*{{.Entity}}, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	i := r.find({{.EntityLower}}ID)
+	if i < 0 {
+		return nil, ErrNotFound
+	}
+	out := *r.live[i]
+	return &out, nil
+}
+
+func (r *memoryRepository) List(ctx context.Context, filter *{{.Entity}}Filter, offset, limit int64, sort map[string]int) ([]*{{.Entity}}, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	conds, err := memoryFilter(filter)
+	if err != nil {
+		return nil, err
+	}
+	r.mu.RLock()
+	var list []*{{.Entity}}
+	for _, e := range r.live {
+		if memoryMatch(e, conds) {
+			v := *e
+			list = append(list, &v)
+		}
+	}
+	r.mu.RUnlock()
+
+	if err := memorySort(list, sort); err != nil {
+		return nil, err
+	}
+	if offset >= int64(len(list)) {
+		return nil, nil
+	}
+	if offset > 0 {
+		list = list[offset:]
+	}
+	if limit > 0 && limit < int64(len(list)) {
+		list = list[:limit]
+	}
+	return list, nil
+}
+
+// ListPage returns the page of {{.EntityLower}}s after (or before) cursor in the
+// order of the cursor index.
+func (r *memoryRepository) ListPage(ctx context.Context, filter *{{.Entity}}Filter, cursor string, limit int64) (*{{.Entity}}Page, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	c, err := parse{{.Entity}}Cursor(cursor)
+	if err != nil {
+		return nil, err
+	}
+	conds, err := memoryFilter(filter)
+	if err != nil {
+		return nil, err
+	}
+	back := c != nil && c.Back
+	r.mu.RLock()
+	var list []*{{.Entity}}
+	for _, e := range r.live {
+		if memoryMatch(e, conds) && (c == nil || memoryKeyset({{.EntityLower}}CursorOf(e, false).values(), c.values(), back) > 0) {
+			v := *e
+			list = append(list, &v)
+		}
+	}
+	r.mu.RUnlock()
+
+	sort.SliceStable(list, func(i, j int) bool {
+		return memoryKeyset({{.EntityLower}}CursorOf(list[i], false).values(), {{.EntityLower}}CursorOf(list[j], false).values(), back) < 0
+	})
+	if limit > 0 && int64(len(list)) > limit+1 {
+		list = list[:limit+1]
+	}
+	return new{{.Entity}}Page(list, c, limit), nil
+}
+
+func (r *memoryRepository) Count(ctx context.Context, filter *{{.Entity}}Filter) (int64, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+	conds, err := memoryFilter(filter)
+	if err != nil {
+		return 0, err
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	var n int64
+	for _, e := range r.live {
+		if memoryMatch(e, conds) {
+			n++
+		}
+	}
+	return n, nil
+}
+
+// UpdateBy{{.Entity}}ID writes the non-zero fields of data, like a $set of
+// the document would.
+func (r *memoryRepository) UpdateBy{{.Entity}}ID(ctx context.Context, {{.EntityLower}}ID string, data *{{.Entity}}) (*{{.Entity}}, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	i := r.find({{.EntityLower}}ID)
+	if i < 0 {
+		return nil, ErrNotFound
+	}
+	v := *r.live[i]
+{{- range memoryFields .Fields}}{{if not (or .Primary (eq .Field $key))}}
+	if !memoryZero(data.{{.Field}}) {
+		v.{{.Field}} = data.{{.Field}}
+	}
+{{- end}}{{end}}
+	if err := r.checkUnique(&v, r.live[i]); err != nil {
+		return nil, err
+	}
+	r.live[i] = &v
+	out := v
+	return &out, nil
+}
+
+// DeleteBy{{.Entity}}ID soft deletes a {{.EntityLower}}: it is kept aside and
+// no longer seen by the other methods.
+func (r *memoryRepository) DeleteBy{{.Entity}}ID(ctx context.Context, {{.EntityLower}}ID string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	i := r.find({{.EntityLower}}ID)
+	if i < 0 {
+		return ErrNotFound
+	}
+	r.deleted = append(r.deleted, r.live[i])
+	r.live = append(r.live[:i], r.live[i+1:]...)
+	return nil
+}
+
+// find returns the index of the live {{.EntityLower}} with the given ID, or -1.
+func (r *memoryRepository) find({{.EntityLower}}ID string) int {
+	for i, e := range r.live {
+		if e.{{$key}} == {{.EntityLower}}ID {
+			return i
+		}
+	}
+	return -1
+}
+
+// checkUnique fails with ErrDuplicate when v collides with a live
+// {{.EntityLower}} other than self on a unique index.
+func (r *memoryRepository) checkUnique(v, self *{{.Entity}}) error {
+	for _, idx := range {{.EntityLower}}UniqueIndexes {
+		key, ok := idx.key(v)
+		if !ok {
+			continue
+		}
+		for _, e := range r.live {
+			if e == self {
+				continue
+			}
+			if other, ok := idx.key(e); ok && memoryEqual(key, other) {
+				return fmt.Errorf("%w: %s", ErrDuplicate, strings.Join(idx.fields, ", "))
+			}
+		}
+	}
+	return nil
+}
+
+// memoryField is a stored field of {{.Entity}}.
+type memoryField struct {
+	bson      string
+	json      string
+	omitEmpty bool // a zero value is not stored
+	get       func(*{{.Entity}}) any
+}
+
+// value returns the stored value of the field, dereferenced; nil when the
+// field is missing from the document.
+func (f memoryField) value(e *{{.Entity}}) any {
+	v := memoryDeref(f.get(e))
+	if f.omitEmpty && memoryZero(v) {
+		return nil
+	}
+	return v
+}
+
+func findMemoryField(name string) (memoryField, bool) {
+	for _, f := range {{.EntityLower}}MemoryFields {
+		if f.bson == name || f.json == name {
+			return f, true
+		}
+	}
+	return memoryField{}, false
+}
+
+// memoryIndex is a unique index. Missing fields index as null, except in
+// sparse indexes, which leave out documents missing all their fields.
+// Partial indexes only hold the documents matching partial.
+type memoryIndex struct {
+	fields  []string
+	sparse  bool
+	partial map[string]any
+}
+
+// key returns the values e is indexed under, or false if the index does
+// not hold e.
+func (idx memoryIndex) key(e *{{.Entity}}) ([]any, bool) {
+	for name, want := range idx.partial {
+		f, _ := findMemoryField(name)
+		if !memoryEqual(f.value(e), want) {
+			return nil, false
+		}
+	}
+	key := make([]any, len(idx.fields))
+	missing := 0
+	for i, name := range idx.fields {
+		f, _ := findMemoryField(name)
+		key[i] = f.value(e)
+		if key[i] == nil {
+			missing++
+		}
+	}
+	if idx.sparse && missing == len(key) {
+		return nil, false
+	}
+	return key, true
+}
+
+// memoryCond is a condition of a filter on a stored field.
+type memoryCond struct {
+	field memoryField
+	op    string
+	value any
+}
+
+// memoryFilter resolves the fields of the conditions of filter.
+func memoryFilter(filter *{{.Entity}}Filter) ([]memoryCond, error) {
+	if filter == nil {
+		return nil, nil
+	}
+	conds := make([]memoryCond, len(filter.conds))
+	for i, c := range filter.conds {
+		f, ok := findMemoryField(c.field)
+		if !ok {
+			return nil, fmt.Errorf("unknown filter field %q", c.field)
+		}
+		conds[i] = memoryCond{field: f, op: c.op, value: c.value}
+	}
+	return conds, nil
+}
+
+// memoryMatch reports whether e matches all conditions. As in MongoDB,
+// comparisons never match a missing field, and $ne and $nin do.
+func memoryMatch(e *{{.Entity}}, conds []memoryCond) bool {
+	for _, c := range conds {
+		v := c.field.value(e)
+		var ok bool
+		switch c.op {
+		case "$eq":
+			ok = memoryEqual(v, c.value)
+		case "$ne":
+			ok = !memoryEqual(v, c.value)
+		case "$in", "$nin":
+			for _, want := range c.value.([]any) {
+				if memoryEqual(v, want) {
+					ok = true
+					break
+				}
+			}
+			ok = ok == (c.op == "$in")
+		case "$gt":
+			ok = v != nil && memoryCompare(v, c.value) > 0
+		case "$gte":
+			ok = v != nil && memoryCompare(v, c.value) >= 0
+		case "$lt":
+			ok = v != nil && memoryCompare(v, c.value) < 0
+		case "$lte":
+			ok = v != nil && memoryCompare(v, c.value) <= 0
+		case "$contains":
+			s, isString := v.(string)
+			ok = isString && strings.Contains(s, c.value.(string))
+		}
+		if !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// memorySort sorts list by a sort spec (1 ascending, -1 descending).
+// Fields are compared in name order, then by insertion order.
+func memorySort(list []*{{.Entity}}, spec map[string]int) error {
+	names := make([]string, 0, len(spec))
+	for k := range spec {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+	fields := make([]memoryField, len(names))
+	for i, name := range names {
+		f, ok := findMemoryField(name)
+		if !ok {
+			return fmt.Errorf("unknown sort field %q", name)
+		}
+		fields[i] = f
+	}
+	sort.SliceStable(list, func(i, j int) bool {
+		for k, f := range fields {
+			c := memoryCompare(f.value(list[i]), f.value(list[j]))
+			if spec[names[k]] < 0 {
+				c = -c
+			}
+			if c != 0 {
+				return c < 0
+			}
+		}
+		return false
+	})
+	return nil
+}
+
+// {{.EntityLower}}CursorDesc tells which fields of the cursor index are
+// in descending order.
+var {{.EntityLower}}CursorDesc = []bool{ {{- range $i, $c := cursorFields .Fields .Indexes $key}}{{if $i}}, {{end}}{{.Desc}}{{end}}}
+
+// memoryKeyset orders two lists of cursor index values in the order of the
+// cursor index, reversed when back is set.
+func memoryKeyset(a, b []any, back bool) int {
+	for i, desc := range {{.EntityLower}}CursorDesc {
+		c := memoryCompare(a[i], b[i])
+		if desc != back {
+			c = -c
+		}
+		if c != 0 {
+			return c
+		}
+	}
+	return 0
+}
+
+// memoryDeref returns the value v points to, or nil for a nil pointer.
+func memoryDeref(v any) any {
+	rv := reflect.ValueOf(v)
+	for rv.Kind() == reflect.Pointer {
+		if rv.IsNil() {
+			return nil
+		}
+		rv = rv.Elem()
+	}
+	if !rv.IsValid() {
+		return nil
+	}
+	return rv.Interface()
+}
+
+// memoryZero reports whether v is nil, a zero value or an empty slice or map.
+func memoryZero(v any) bool {
+	rv := reflect.ValueOf(v)
+	if !rv.IsValid() {
+		return true
+	}
+	switch rv.Kind() {
+	case reflect.Slice, reflect.Map:
+		return rv.Len() == 0
+	}
+	return rv.IsZero()
+}
+
+// memoryEqual reports whether two values are equal. Numbers compare by
+// value whatever their type, nil only equals nil, and slices compare
+// element by element.
+func memoryEqual(a, b any) bool {
+	a, b = memoryDeref(a), memoryDeref(b)
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	_, scalarA := memoryScalar(a)
+	_, scalarB := memoryScalar(b)
+	if scalarA || scalarB {
+		return scalarA && scalarB && memoryCompare(a, b) == 0
+	}
+	ra, rb := reflect.ValueOf(a), reflect.ValueOf(b)
+	if (ra.Kind() == reflect.Slice || ra.Kind() == reflect.Array) && (rb.Kind() == reflect.Slice || rb.Kind() == reflect.Array) {
+		if ra.Len() != rb.Len() {
+			return false
+		}
+		for i := 0; i < ra.Len(); i++ {
+			if !memoryEqual(ra.Index(i).Interface(), rb.Index(i).Interface()) {
+				return false
+			}
+		}
+		return true
+	}
+	return reflect.DeepEqual(a, b)
+}
+
+// memoryCompare orders two values: nil first, then numbers, strings,
+// booleans and times, then anything else by its printed form.
+func memoryCompare(a, b any) int {
+	a, b = memoryDeref(a), memoryDeref(b)
+	switch {
+	case a == nil && b == nil:
+		return 0
+	case a == nil:
+		return -1
+	case b == nil:
+		return 1
+	}
+	sa, oka := memoryScalar(a)
+	sb, okb := memoryScalar(b)
+	if !oka || !okb || sa.rank != sb.rank {
+		if oka && okb {
+			return sa.rank - sb.rank
+		}
+		return strings.Compare(fmt.Sprint(a), fmt.Sprint(b))
+	}
+	switch v := sa.v.(type) {
+	case float64:
+		w := sb.v.(float64)
+		switch {
+		case v < w:
+			return -1
+		case v > w:
+			return 1
+		}
+		return 0
+	case string:
+		return strings.Compare(v, sb.v.(string))
+	case bool:
+		w := sb.v.(bool)
+		switch {
+		case v == w:
+			return 0
+		case w:
+			return -1
+		}
+		return 1
+	case time.Time:
+		return v.Compare(sb.v.(time.Time))
+	}
+	return 0
+}
+
+// memoryScalarValue is a comparable value and the rank of its kind.
+type memoryScalarValue struct {
+	v    any
+	rank int
+}
+
+// memoryScalar normalizes numbers, strings, booleans and times, including
+// named types of them.
+func memoryScalar(v any) (memoryScalarValue, bool) {
+	if t, ok := v.(time.Time); ok {
+		return memoryScalarValue{t, 3}, true
+	}
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return memoryScalarValue{float64(rv.Int()), 0}, true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return memoryScalarValue{float64(rv.Uint()), 0}, true
+	case reflect.Float32, reflect.Float64:
+		return memoryScalarValue{rv.Float(), 0}, true
+	case reflect.String:
+		return memoryScalarValue{rv.String(), 1}, true
+	case reflect.Bool:
+		return memoryScalarValue{rv.Bool(), 2}, true
+	}
+	return memoryScalarValue{}, false
+}
+`
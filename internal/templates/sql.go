@@ -0,0 +1,462 @@
+package templates
+
+// Model layer of the SQL backends, on database/sql. The schema comes from
+// sqlSchema and the column lists from sqlColumns. Table and column names
+// are quoted, so reserved words such as "order" work as names. Placeholders
+// are numbered, $N for PostgreSQL drivers (pgx, lib/pq) and ?N for SQLite
+// drivers.
+
+var SQLInit = `package {{.Package}}
+
+import (
+	"context"
+	"database/sql"
+)
+{{- if ne .Style "di"}}
+
+var {{.EntityLower}}Repository Repository
+{{- end}}
+
+// {{.EntityLower}}Schema creates the {{.DBName}} table and its indexes.
+var {{.EntityLower}}Schema = []string{
+{{- range sqlSchema .Dialect .DBName .Fields .Indexes (printf "%sID" .Entity)}}
+	` + "`{{.}}`" + `,
+{{- end}}
+}
+{{if eq .Style "di"}}
+// Option configures NewRepository.
+type Option func(*repositoryOptions)
+
+type repositoryOptions struct {
+	noSchema bool
+}
+
+// WithoutSchema skips creating the table and its indexes, e.g. when they
+// are managed by migrations.
+func WithoutSchema() Option {
+	return func(o *repositoryOptions) { o.noSchema = true }
+}
+
+// NewRepository returns the repository of the {{.EntityLower}}s in db and
+// creates their table and indexes.
+func NewRepository(ctx context.Context, db *sql.DB, opts ...Option) (Repository, error) {
+	var o repositoryOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	if !o.noSchema {
+		for _, stmt := range {{.EntityLower}}Schema {
+			if _, err := db.ExecContext(ctx, stmt); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return &sqlRepository{db: db}, nil
+}
+{{- else}}
+// Init sets up the {{.EntityLower}} repository on db and creates its table and
+// indexes.
+func Init(ctx context.Context, db *sql.DB) error {
+	// Initialize repository
+	{{.EntityLower}}Repository = &sqlRepository{db: db}
+
+	// Create the table and its indexes
+	for _, stmt := range {{.EntityLower}}Schema {
+		if _, err := db.ExecContext(ctx, stmt); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// GetRepository returns the initialized repository instance
+func GetRepository() Repository {
+	return {{.EntityLower}}Repository
+}
+
+// SetRepository replaces the repository GetRepository returns, for
+// instance with NewMemoryRepository() in tests. Call it before the
+// repository is used.
+func SetRepository(repo Repository) {
+	{{.EntityLower}}Repository = repo
+}
+{{- end}}
+`
+
+var SQLRepository = `package {{.Package}}
+{{- $key := sqlColumn .Fields (printf "%sID" .Entity)}}
+{{- $cols := sqlColumns .Fields}}
+{{- $p := sqlParam .Dialect}}
+{{- $deleted := sqlIdent "deleted_at"}}
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+` + repositoryInterface + `
+// sqlRepository implements the Repository interface on database/sql.
+// Deletes are soft: they set the deleted_at time of the row, and the other
+// methods ignore deleted rows.
+type sqlRepository struct {
+	db *sql.DB
+}
+
+// {{.EntityLower}}Columns are the columns of the {{.DBName}} table.
+var {{.EntityLower}}Columns = []sqlColumn{
+{{- range $cols}}
+	{name: "{{.Name}}", ident: "{{sqlIdent .Name}}", json: "{{.JSONName}}"{{if .JSON}}, isJSON: true{{end}}},
+{{- end}}
+}
+
+const {{.EntityLower}}Select = "SELECT {{sqlNames $cols}} FROM {{sqlIdent .DBName}}"
+
+func (r *sqlRepository) Create(ctx context.Context, data *{{.Entity}}) (*{{.Entity}}, error) {
+	row := r.db.QueryRowContext(ctx, "INSERT INTO {{sqlIdent .DBName}} ({{sqlNames $cols}}) VALUES ({{sqlPlaceholders $p (len $cols)}}) RETURNING {{sqlNames $cols}}",
+		{{range $i, $c := $cols}}{{if $i}}, {{end}}{{$c.Arg "data"}}{{end}})
+	return scan{{.Entity}}(row)
+}
+
+func (r *sqlRepository) GetBy{{.Entity}}ID(ctx context.Context, {{.EntityLower}}ID string) (*{{.Entity}}, error) {
+	row := r.db.QueryRowContext(ctx, {{.EntityLower}}Select+" WHERE {{sqlIdent $key}} = {{$p}}1 AND {{$deleted}} IS NULL", {{.EntityLower}}ID)
+	return scan{{.Entity}}(row)
+}
+
+func (r *sqlRepository) List(ctx context.Context, filter *{{.Entity}}Filter, offset, limit int64, sort map[string]int) ([]*{{.Entity}}, error) {
+	where, args, err := sqlWhere({{.EntityLower}}Columns, filter)
+	if err != nil {
+		return nil, err
+	}
+	order, err := sqlOrder({{.EntityLower}}Columns, sort, "{{sqlIdent $key}}")
+	if err != nil {
+		return nil, err
+	}
+	query := {{.EntityLower}}Select + where + order
+	if limit > 0 {
+		query += fmt.Sprintf(" LIMIT %d", limit)
+	}
+	{{- if eq .Dialect "sqlite"}} else if offset > 0 {
+		// SQLite only takes an OFFSET after a LIMIT
+		query += " LIMIT -1"
+	}
+	{{- end}}
+	if offset > 0 {
+		query += fmt.Sprintf(" OFFSET %d", offset)
+	}
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var list []*{{.Entity}}
+	for rows.Next() {
+		data, err := scan{{.Entity}}(rows)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, data)
+	}
+	return list, rows.Err()
+}
+
+func (r *sqlRepository) Count(ctx context.Context, filter *{{.Entity}}Filter) (int64, error) {
+	where, args, err := sqlWhere({{.EntityLower}}Columns, filter)
+	if err != nil {
+		return 0, err
+	}
+	var n int64
+	err = r.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM {{sqlIdent .DBName}}"+where, args...).Scan(&n)
+	return n, err
+}
+
+// ListPage returns the page of {{.EntityLower}}s after (or before) cursor in the
+// order of the cursor index.
+func (r *sqlRepository) ListPage(ctx context.Context, filter *{{.Entity}}Filter, cursor string, limit int64) (*{{.Entity}}Page, error) {
+	c, err := parse{{.Entity}}Cursor(cursor)
+	if err != nil {
+		return nil, err
+	}
+	where, args, err := sqlWhere({{.EntityLower}}Columns, filter)
+	if err != nil {
+		return nil, err
+	}
+	back := c != nil && c.Back
+	if c != nil {
+		where += " AND " + sqlKeyset(c.values(), back, &args)
+	}
+	order := make([]string, len({{.EntityLower}}CursorKeys))
+	for i, k := range {{.EntityLower}}CursorKeys {
+		order[i] = k.name
+		if k.desc != back {
+			order[i] += " DESC"
+		}
+	}
+	query := {{.EntityLower}}Select + where + " ORDER BY " + strings.Join(order, ", ")
+	if limit > 0 {
+		query += fmt.Sprintf(" LIMIT %d", limit+1)
+	}
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []*{{.Entity}}
+	for rows.Next() {
+		data, err := scan{{.Entity}}(rows)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, data)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return new{{.Entity}}Page(items, c, limit), nil
+}
+
+// UpdateBy{{.Entity}}ID writes the non-zero fields of data, like a $set of
+// the document would.
+func (r *sqlRepository) UpdateBy{{.Entity}}ID(ctx context.Context, {{.EntityLower}}ID string, data *{{.Entity}}) (*{{.Entity}}, error) {
+	var sets []string
+	var args []any
+	for _, f := range []struct {
+		column string // quoted for SQL
+		value  any
+		isJSON bool
+	}{
+{{- range $cols}}{{if not (or .Primary (eq .Name $key))}}
+		{"{{sqlIdent .Name}}", data.{{.Field}}, {{.JSON}}},
+{{- end}}{{end}}
+	} {
+		if isZero(f.value) {
+			continue
+		}
+		if f.isJSON {
+			f.value = jsonValue{f.value}
+		}
+		args = append(args, f.value)
+		sets = append(sets, fmt.Sprintf("%s = {{$p}}%d", f.column, len(args)))
+	}
+	if len(sets) == 0 {
+		return r.GetBy{{.Entity}}ID(ctx, {{.EntityLower}}ID)
+	}
+
+	args = append(args, {{.EntityLower}}ID)
+	query := fmt.Sprintf("UPDATE {{sqlIdent .DBName}} SET %s WHERE {{sqlIdent $key}} = {{$p}}%d AND {{$deleted}} IS NULL RETURNING {{sqlNames $cols}}", strings.Join(sets, ", "), len(args))
+	return scan{{.Entity}}(r.db.QueryRowContext(ctx, query, args...))
+}
+
+// DeleteBy{{.Entity}}ID soft deletes a {{.EntityLower}} by setting its deleted_at time
+func (r *sqlRepository) DeleteBy{{.Entity}}ID(ctx context.Context, {{.EntityLower}}ID string) error {
+	res, err := r.db.ExecContext(ctx, "UPDATE {{sqlIdent .DBName}} SET {{$deleted}} = CURRENT_TIMESTAMP WHERE {{sqlIdent $key}} = {{$p}}1 AND {{$deleted}} IS NULL", {{.EntityLower}}ID)
+	if err != nil {
+		return err
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// rowScanner is implemented by *sql.Row and *sql.Rows.
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+func scan{{.Entity}}(row rowScanner) (*{{.Entity}}, error) {
+	var data {{.Entity}}
+	if err := row.Scan({{range $i, $c := $cols}}{{if $i}}, {{end}}{{$c.Dest "data"}}{{end}}); err != nil {
+		return nil, err
+	}
+	return &data, nil
+}
+
+// sqlColumn is a table column with the JSON name query filters use.
+type sqlColumn struct {
+	name   string
+	ident  string // name quoted for SQL
+	json   string
+	isJSON bool // stored as JSON
+}
+
+func findColumn(columns []sqlColumn, name string) (sqlColumn, bool) {
+	for _, c := range columns {
+		if c.name == name || c.json == name {
+			return c, true
+		}
+	}
+	return sqlColumn{}, false
+}
+
+// sqlWhere translates a filter into a WHERE clause over the live rows.
+// Like MongoDB, $ne and $nin also match NULL.
+func sqlWhere(columns []sqlColumn, filter *{{.Entity}}Filter) (string, []any, error) {
+	conds := []string{"{{$deleted}} IS NULL"}
+	var args []any
+	param := func(v any) string {
+		args = append(args, v)
+		return fmt.Sprintf("{{$p}}%d", len(args))
+	}
+	var list []filterCondition
+	if filter != nil {
+		list = filter.conds
+	}
+	for _, c := range list {
+		col, ok := findColumn(columns, c.field)
+		if !ok {
+			return "", nil, fmt.Errorf("unknown filter field %q", c.field)
+		}
+		name := col.ident
+		switch c.op {
+		case "$eq":
+			conds = append(conds, name+" = "+param(c.value))
+		case "$ne":
+			conds = append(conds, "("+name+" IS NULL OR "+name+" <> "+param(c.value)+")")
+		case "$in", "$nin":
+			values := c.value.([]any)
+			if len(values) == 0 {
+				if c.op == "$in" {
+					conds = append(conds, "1 = 0")
+				}
+				continue
+			}
+			ps := make([]string, len(values))
+			for i, v := range values {
+				ps[i] = param(v)
+			}
+			if c.op == "$in" {
+				conds = append(conds, name+" IN ("+strings.Join(ps, ", ")+")")
+			} else {
+				conds = append(conds, "("+name+" IS NULL OR "+name+" NOT IN ("+strings.Join(ps, ", ")+"))")
+			}
+		case "$gt", "$gte", "$lt", "$lte":
+			conds = append(conds, name+" "+sqlOperators[c.op]+" "+param(c.value))
+		case "$contains":
+			conds = append(conds, "{{if eq .Dialect "sqlite"}}instr{{else}}strpos{{end}}("+name+", "+param(c.value)+") > 0")
+		default:
+			return "", nil, fmt.Errorf("unknown filter operator %s", c.op)
+		}
+	}
+	return " WHERE " + strings.Join(conds, " AND "), args, nil
+}
+
+// sqlKey is a column of the cursor index.
+type sqlKey struct {
+	name string // quoted for SQL
+	desc bool
+}
+
+// {{.EntityLower}}CursorKeys are the columns of the cursor index.
+var {{.EntityLower}}CursorKeys = []sqlKey{
+{{- range cursorFields .Fields .Indexes (printf "%sID" .Entity)}}
+	{name: "{{sqlIdent .Column}}"{{if .Desc}}, desc: true{{end}}},
+{{- end}}
+}
+
+// sqlKeyset returns the condition selecting the rows after values in the
+// order of the cursor index, or before them when back is set. Its
+// arguments are appended to args.
+func sqlKeyset(values []any, back bool, args *[]any) string {
+	param := func(v any) string {
+		*args = append(*args, v)
+		return fmt.Sprintf("{{$p}}%d", len(*args))
+	}
+	or := make([]string, len({{.EntityLower}}CursorKeys))
+	for i, k := range {{.EntityLower}}CursorKeys {
+		var and []string
+		for j := 0; j < i; j++ {
+			and = append(and, {{.EntityLower}}CursorKeys[j].name+" = "+param(values[j]))
+		}
+		op := " > "
+		if k.desc != back {
+			op = " < "
+		}
+		or[i] = "(" + strings.Join(append(and, k.name+op+param(values[i])), " AND ") + ")"
+	}
+	return "(" + strings.Join(or, " OR ") + ")"
+}
+
+// sqlOperators are the SQL forms of the comparison operators of filters.
+var sqlOperators = map[string]string{"$gt": ">", "$gte": ">=", "$lt": "<", "$lte": "<="}
+
+// sqlOrder translates a sort spec (1 ascending, -1 descending) into an
+// ORDER BY clause. Columns are ordered by name, and the key column, quoted,
+// comes last so that pages are stable.
+func sqlOrder(columns []sqlColumn, spec map[string]int, key string) (string, error) {
+	names := make([]string, 0, len(spec))
+	for k := range spec {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+
+	var terms []string
+	hasKey := false
+	for _, k := range names {
+		c, ok := findColumn(columns, k)
+		if !ok {
+			return "", fmt.Errorf("unknown sort field %q", k)
+		}
+		hasKey = hasKey || c.ident == key
+		if spec[k] < 0 {
+			terms = append(terms, c.ident+" DESC")
+		} else {
+			terms = append(terms, c.ident)
+		}
+	}
+	if !hasKey {
+		terms = append(terms, key)
+	}
+	return " ORDER BY " + strings.Join(terms, ", "), nil
+}
+
+// isZero reports whether v is nil, a zero value or an empty slice or map.
+func isZero(v any) bool {
+	rv := reflect.ValueOf(v)
+	if !rv.IsValid() {
+		return true
+	}
+	switch rv.Kind() {
+	case reflect.Slice, reflect.Map:
+		return rv.Len() == 0
+	}
+	return rv.IsZero()
+}
+
+// jsonValue writes a field stored as JSON.
+type jsonValue struct{ v any }
+
+func (j jsonValue) Value() (driver.Value, error) {
+	b, err := json.Marshal(j.v)
+	return string(b), err
+}
+
+// jsonDest reads a field stored as JSON.
+type jsonDest struct{ v any }
+
+func (j jsonDest) Scan(src any) error {
+	switch s := src.(type) {
+	case nil:
+		return nil
+	case []byte:
+		return json.Unmarshal(s, j.v)
+	case string:
+		return json.Unmarshal([]byte(s), j.v)
+	}
+	return fmt.Errorf("cannot scan %T into a JSON column", src)
+}
+`
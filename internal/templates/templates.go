@@ -1,33 +1,104 @@
 package templates
 
-var ModelInit = `package {{.Entity | lower}}
+var ModelInit = `package {{.Package}}
 
 import (
+	"context"
+
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
 
-	"gitlab.silvertiger.tech/go-sdk/go-mongodb/collection"
+	"{{.CollectionImport}}"
 	"{{.Module}}/internal/utils"
 )
+{{if eq .Style "di"}}
+// Option configures NewRepository.
+type Option func(*repositoryOptions)
+
+type repositoryOptions struct {
+	collection string
+	deleted    string
+	noIndexes  bool
+}
+
+// WithCollection stores the {{.EntityLower}}s in the collection name instead of
+// "{{.DBName}}". Deleted {{.EntityLower}}s still go to "{{.EntitySnake}}_deleted"; see
+// WithDeletedCollection.
+func WithCollection(name string) Option {
+	return func(o *repositoryOptions) { o.collection = name }
+}
+
+// WithDeletedCollection moves deleted {{.EntityLower}}s to the collection name
+// instead of "{{.EntitySnake}}_deleted".
+func WithDeletedCollection(name string) Option {
+	return func(o *repositoryOptions) { o.deleted = name }
+}
+
+// WithoutIndexes skips creating the indexes, e.g. when they are managed
+// by migrations.
+func WithoutIndexes() Option {
+	return func(o *repositoryOptions) { o.noIndexes = true }
+}
+
+// NewRepository returns the repository of the {{.EntityLower}}s in database and
+// creates their indexes. The collections take no context, so once ctx is
+// done it returns ctx.Err() instead of creating the indexes.
+func NewRepository(ctx context.Context, database *mongo.Database, opts ...Option) (Repository, error) {
+	o := repositoryOptions{collection: "{{.DBName}}", deleted: "{{.EntitySnake}}_deleted"}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	deleted := collection.NewMongoDBGenericCollection[{{.Entity}}](o.deleted).(*collection.MongoDBGenericCollection[{{.Entity}}])
+	deleted.SetDatabase(database)
+
+	{{.EntityLower}}Collection := collection.NewMongoDBGenericCollection[{{.Entity}}](o.collection).(*collection.MongoDBGenericCollection[{{.Entity}}])
+	{{.EntityLower}}Collection.SetDatabase(database)
 
+	if !o.noIndexes {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		if err := createIndexes({{.EntityLower}}Collection); err != nil {
+			return nil, err
+		}
+	}
+
+	return &mongoRepository{collection: {{.EntityLower}}Collection, deleted: deleted, driver: database.Collection(o.collection)}, nil
+}
+
+func createIndexes({{.EntityLower}}Collection *collection.MongoDBGenericCollection[{{.Entity}}]) error {
+{{if hasIndexes .Fields .Indexes}}{{generateIndexes .Fields .Indexes .EntityLower}}{{else}}	// No indexes defined{{end}}
+
+	return nil
+}
+{{else}}
 var (
 	{{.EntityLower}}Collection        *collection.MongoDBGenericCollection[{{.Entity}}]
 	{{.EntityLower}}DeletedCollection *collection.MongoDBGenericCollection[{{.Entity}}]
+	{{.EntityLower}}DriverCollection  *mongo.Collection // the same collection on the driver, for ListPage
 	{{.EntityLower}}Repository        Repository
 )
 
-func Init(database *mongo.Database) error {
+// Init sets up the {{.EntityLower}} collections and creates their indexes. The
+// collections take no context, so once ctx is done it returns ctx.Err()
+// instead of creating the indexes.
+func Init(ctx context.Context, database *mongo.Database) error {
 	{{.EntityLower}}DeletedCollection = collection.NewMongoDBGenericCollection[{{.Entity}}]("{{.EntitySnake}}_deleted").(*collection.MongoDBGenericCollection[{{.Entity}}])
 	{{.EntityLower}}DeletedCollection.SetDatabase(database)
 
 	{{.EntityLower}}Collection = collection.NewMongoDBGenericCollection[{{.Entity}}]("{{.DBName}}").(*collection.MongoDBGenericCollection[{{.Entity}}])
 	{{.EntityLower}}Collection.SetDatabase(database)
+	{{.EntityLower}}DriverCollection = database.Collection("{{.DBName}}")
 
 	// Initialize repository
 	{{.EntityLower}}Repository = &mongoRepository{}
 
 	// Create indexes
+	if err := ctx.Err(); err != nil {
+		return err
+	}
 	if err := createIndexes(); err != nil {
 		return err
 	}
@@ -40,119 +111,295 @@ func GetRepository() Repository {
 	return {{.EntityLower}}Repository
 }
 
+// SetRepository replaces the repository GetRepository returns, for
+// instance with NewMemoryRepository() in tests. Call it before the
+// repository is used.
+func SetRepository(repo Repository) {
+	{{.EntityLower}}Repository = repo
+}
+
 func createIndexes() error {
 {{if hasIndexes .Fields .Indexes}}{{generateIndexes .Fields .Indexes .EntityLower}}{{else}}	// No indexes defined{{end}}
 
 	return nil
 }
+{{end -}}
 `
 
-var ModelRepository = `package {{.Entity | lower}}
-
-// Repository defines the interface for {{.EntityLower}} operations
+// repositoryInterface is the Repository interface every storage backend
+// implements.
+const repositoryInterface = `// Repository defines the interface for {{.EntityLower}} operations
 type Repository interface {
-	Create(data *{{.Entity}}) (*{{.Entity}}, error)
-	GetBy{{.Entity}}ID({{.EntityLower}}ID string) (*{{.Entity}}, error)
-	List(filter interface{}, offset, limit int64, sort map[string]int) ([]*{{.Entity}}, error)
-	Count(filter interface{}) (int64, error)
-	UpdateBy{{.Entity}}ID({{.EntityLower}}ID string, data *{{.Entity}}) (*{{.Entity}}, error)
-	DeleteBy{{.Entity}}ID({{.EntityLower}}ID string) error
+	Create(ctx context.Context, data *{{.Entity}}) (*{{.Entity}}, error)
+	GetBy{{.Entity}}ID(ctx context.Context, {{.EntityLower}}ID string) (*{{.Entity}}, error)
+	List(ctx context.Context, filter *{{.Entity}}Filter, offset, limit int64, sort map[string]int) ([]*{{.Entity}}, error)
+	Count(ctx context.Context, filter *{{.Entity}}Filter) (int64, error)
+	ListPage(ctx context.Context, filter *{{.Entity}}Filter, cursor string, limit int64) (*{{.Entity}}Page, error)
+	UpdateBy{{.Entity}}ID(ctx context.Context, {{.EntityLower}}ID string, data *{{.Entity}}) (*{{.Entity}}, error)
+	DeleteBy{{.Entity}}ID(ctx context.Context, {{.EntityLower}}ID string) error
 }
+`
+
+var ModelRepository = `package {{.Package}}
+{{- $collection := printf "%sCollection" .EntityLower}}
+{{- $deleted := printf "%sDeletedCollection" .EntityLower}}
+{{- $driver := printf "%sDriverCollection" .EntityLower}}
+{{- if eq .Style "di"}}{{$collection = "r.collection"}}{{$deleted = "r.deleted"}}{{$driver = "r.driver"}}{{end}}
 
-// mongoRepository implements the Repository interface
+import (
+	"context"
+	"regexp"
+{{if eq .Style "di"}}
+	"{{.CollectionImport}}"
+{{- end}}
+	"go.mongodb.org/mongo-driver/bson"
+{{- if eq .Style "di"}}
+	"go.mongodb.org/mongo-driver/mongo"
+{{- end}}
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+` + repositoryInterface + `
+// mongoRepository implements the Repository interface. The collection
+// calls take no context, so every method returns ctx.Err() instead of
+// starting one once ctx is done.
+{{- if eq .Style "di"}}
+type mongoRepository struct {
+	collection *collection.MongoDBGenericCollection[{{.Entity}}]
+	deleted    *collection.MongoDBGenericCollection[{{.Entity}}] // soft-deleted {{.EntityLower}}s
+	driver     *mongo.Collection                                  // the same collection on the driver, for ListPage
+}
+{{- else}}
 type mongoRepository struct{}
+{{- end}}
+
+func (r *mongoRepository) Create(ctx context.Context, data *{{.Entity}}) (*{{.Entity}}, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return {{$collection}}.InsertOne(data)
+}
 
-func (r *mongoRepository) Create(data *{{.Entity}}) (*{{.Entity}}, error) {
-	return {{.EntityLower}}Collection.InsertOne(data)
+func (r *mongoRepository) GetBy{{.Entity}}ID(ctx context.Context, {{.EntityLower}}ID string) (*{{.Entity}}, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return {{$collection}}.FindOne({{.Entity}}{ {{.Entity}}ID: {{.EntityLower}}ID})
 }
 
-func (r *mongoRepository) GetBy{{.Entity}}ID({{.EntityLower}}ID string) (*{{.Entity}}, error) {
-	return {{.EntityLower}}Collection.FindOne({{.Entity}}{ {{.Entity}}ID: {{.EntityLower}}ID})
+func (r *mongoRepository) List(ctx context.Context, filter *{{.Entity}}Filter, offset, limit int64, sort map[string]int) ([]*{{.Entity}}, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return {{$collection}}.Find(filter.query(), offset, limit, sort)
 }
 
-func (r *mongoRepository) List(filter interface{}, offset, limit int64, sort map[string]int) ([]*{{.Entity}}, error) {
-	return {{.EntityLower}}Collection.Find(filter, offset, limit, sort)
+func (r *mongoRepository) Count(ctx context.Context, filter *{{.Entity}}Filter) (int64, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+	return {{$collection}}.Count(filter.query())
 }
 
-func (r *mongoRepository) Count(filter interface{}) (int64, error) {
-	return {{.EntityLower}}Collection.Count(filter)
+// ListPage returns the page of {{.EntityLower}}s after (or before) cursor in the
+// order of the cursor index. It queries the collection through the driver,
+// whose sort document keeps the order of the index fields.
+func (r *mongoRepository) ListPage(ctx context.Context, filter *{{.Entity}}Filter, cursor string, limit int64) (*{{.Entity}}Page, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	c, err := parse{{.Entity}}Cursor(cursor)
+	if err != nil {
+		return nil, err
+	}
+	back := c != nil && c.Back
+	query := filter.query()
+	if c != nil {
+		query = bson.D{bson.E{Key: "$and", Value: bson.A{query, mongoKeyset(c.values(), back)}}}
+	}
+	sort := bson.D{}
+	for _, k := range {{.EntityLower}}CursorKeys {
+		direction := 1
+		if k.desc != back {
+			direction = -1
+		}
+		sort = append(sort, bson.E{Key: k.name, Value: direction})
+	}
+	opts := options.Find().SetSort(sort)
+	if limit > 0 {
+		opts.SetLimit(limit + 1)
+	}
+	cur, err := {{$driver}}.Find(ctx, query, opts)
+	if err != nil {
+		return nil, err
+	}
+	var items []*{{.Entity}}
+	if err := cur.All(ctx, &items); err != nil {
+		return nil, err
+	}
+	return new{{.Entity}}Page(items, c, limit), nil
 }
 
-func (r *mongoRepository) UpdateBy{{.Entity}}ID({{.EntityLower}}ID string, data *{{.Entity}}) (*{{.Entity}}, error) {
-	return {{.EntityLower}}Collection.UpdateOne({{.Entity}}{ {{.Entity}}ID: {{.EntityLower}}ID}, data)
+func (r *mongoRepository) UpdateBy{{.Entity}}ID(ctx context.Context, {{.EntityLower}}ID string, data *{{.Entity}}) (*{{.Entity}}, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return {{$collection}}.UpdateOne({{.Entity}}{ {{.Entity}}ID: {{.EntityLower}}ID}, data)
 }
 
 // Delete implements Repository.Delete - Soft delete by moving to {{.EntitySnake}}_deleted collection
-func (r *mongoRepository) DeleteBy{{.Entity}}ID({{.EntityLower}}ID string) error {
-	{{.EntityLower}}, err := {{.EntityLower}}Collection.FindOne({{.Entity}}{ {{.Entity}}ID: {{.EntityLower}}ID})
+func (r *mongoRepository) DeleteBy{{.Entity}}ID(ctx context.Context, {{.EntityLower}}ID string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	{{.EntityLower}}, err := {{$collection}}.FindOne({{.Entity}}{ {{.Entity}}ID: {{.EntityLower}}ID})
 	if err != nil {
 		return err
 	}
-	_, err = {{.EntityLower}}DeletedCollection.InsertOne({{.EntityLower}})
+	_, err = {{$deleted}}.InsertOne({{.EntityLower}})
 	if err != nil {
 		return err
 	}
-	return {{.EntityLower}}Collection.DeleteOne({{.Entity}}{ {{.Entity}}ID: {{.EntityLower}}ID})
+	return {{$collection}}.DeleteOne({{.Entity}}{ {{.Entity}}ID: {{.EntityLower}}ID})
+}
+
+// {{.EntityLower}}BSONNames maps the JSON names of filters to bson names.
+var {{.EntityLower}}BSONNames = map[string]string{
+{{- range filterFields .Fields}}
+	"{{.JSONName}}": "{{.BSONName}}",
+{{- end}}
+}
+
+// mongoKey is a field of the cursor index.
+type mongoKey struct {
+	name string
+	desc bool
+}
+
+// {{.EntityLower}}CursorKeys are the fields of the cursor index.
+var {{.EntityLower}}CursorKeys = []mongoKey{
+{{- range cursorFields .Fields .Indexes (printf "%sID" .Entity)}}
+	{name: "{{.BSONName}}"{{if .Desc}}, desc: true{{end}}},
+{{- end}}
+}
+
+// mongoKeyset selects the documents after values in the order of the
+// cursor index, or before them when back is set.
+func mongoKeyset(values []any, back bool) bson.D {
+	or := make(bson.A, len({{.EntityLower}}CursorKeys))
+	for i, k := range {{.EntityLower}}CursorKeys {
+		cond := bson.D{}
+		for j := 0; j < i; j++ {
+			cond = append(cond, bson.E{Key: {{.EntityLower}}CursorKeys[j].name, Value: values[j]})
+		}
+		op := "$gt"
+		if k.desc != back {
+			op = "$lt"
+		}
+		or[i] = append(cond, bson.E{Key: k.name, Value: bson.D{bson.E{Key: op, Value: values[i]}}})
+	}
+	return bson.D{bson.E{Key: "$or", Value: or}}
+}
+
+// query translates the filter into a MongoDB query document.
+func (f *{{.Entity}}Filter) query() bson.D {
+	if f == nil || len(f.conds) == 0 {
+		return bson.D{}
+	}
+	all := make(bson.A, len(f.conds))
+	for i, c := range f.conds {
+		op, value := c.op, c.value
+		if op == "$contains" {
+			op, value = "$regex", regexp.QuoteMeta(value.(string))
+		}
+		all[i] = bson.D{bson.E{Key: {{.EntityLower}}BSONNames[c.field], Value: bson.D{bson.E{Key: op, Value: value}}}}
+	}
+	return bson.D{bson.E{Key: "$and", Value: all}}
 }
 `
 
 var Action = `package action
+{{- $recv := ""}}
+{{- $repo := printf "%s.GetRepository()" .Package}}
+{{- if eq .Style "di"}}{{$recv = printf "(s *%sService) " .Entity}}{{$repo = "s.repo"}}{{end}}
 
 import (
-	"gitlab.silvertiger.tech/go-sdk/go-common/common"
-	"{{.Module}}/{{.PkgPath}}"
+	"context"
+
+	"{{.CommonImport}}"
+	"{{.ModelImport}}"
 )
+{{- if eq .Style "di"}}
+
+// {{.Entity}}Service implements the {{.EntityLower}} actions on a repository.
+type {{.Entity}}Service struct {
+	repo {{.Package}}.Repository
+}
+
+// New{{.Entity}}Service returns the {{.EntityLower}} actions on repo.
+func New{{.Entity}}Service(repo {{.Package}}.Repository) *{{.Entity}}Service {
+	return &{{.Entity}}Service{repo: repo}
+}
+{{- end}}
 
 // Create{{.Entity}} creates a new {{.EntityLower}}
-func Create{{.Entity}}(data *{{.EntityLower}}.{{.Entity}}) *common.APIResponse[*{{.EntityLower}}.{{.Entity}}] {
-	repo := {{.EntityLower}}.GetRepository()
+func {{$recv}}Create{{.Entity}}(ctx context.Context, data *{{.Package}}.{{.Entity}}) *common.APIResponse[*{{.Package}}.{{.Entity}}] {
+	repo := {{$repo}}
 
-	result, err := repo.Create(data)
+	result, err := repo.Create(ctx, data)
 
 	if err != nil {
 		// Convert CommonResponse to typed response
 		errorResp := common.FromError(err)
-		return &common.APIResponse[*{{.EntityLower}}.{{.Entity}}]{
+		return &common.APIResponse[*{{.Package}}.{{.Entity}}]{
 			Status:    common.APIStatus.Invalid,
 			Message:   errorResp.GetMessage(),
 			ErrorCode: errorResp.GetErrorCode(),
 		}
 	}
 
-	return &common.APIResponse[*{{.EntityLower}}.{{.Entity}}]{
+	return &common.APIResponse[*{{.Package}}.{{.Entity}}]{
 		Status:  common.APIStatus.Ok,
-		Data:    []*{{.EntityLower}}.{{.Entity}}{result},
+		Data:    []*{{.Package}}.{{.Entity}}{result},
 		Message: "{{.Entity}} created successfully",
 	}
 }
 
 // Get{{.Entity}}By{{.Entity}}ID retrieves a {{.EntityLower}} by its {{.Entity}}ID
-func Get{{.Entity}}By{{.Entity}}ID({{.EntityLower}}ID string) *common.APIResponse[*{{.EntityLower}}.{{.Entity}}] {
-	repo := {{.EntityLower}}.GetRepository()
+func {{$recv}}Get{{.Entity}}By{{.Entity}}ID(ctx context.Context, {{.EntityLower}}ID string) *common.APIResponse[*{{.Package}}.{{.Entity}}] {
+	repo := {{$repo}}
 
-	result, err := repo.GetBy{{.Entity}}ID({{.EntityLower}}ID)
+	result, err := repo.GetBy{{.Entity}}ID(ctx, {{.EntityLower}}ID)
 	if err != nil {
 		// Convert CommonResponse to typed response
 		errorResp := common.FromError(err)
-		return &common.APIResponse[*{{.EntityLower}}.{{.Entity}}]{
+		return &common.APIResponse[*{{.Package}}.{{.Entity}}]{
 			Status:    errorResp.GetStatus(),
 			Message:   errorResp.GetMessage(),
 			ErrorCode: errorResp.GetErrorCode(),
 		}
 	}
 
-	return &common.APIResponse[*{{.EntityLower}}.{{.Entity}}]{
+	return &common.APIResponse[*{{.Package}}.{{.Entity}}]{
 		Status:  common.APIStatus.Ok,
-		Data:    []*{{.EntityLower}}.{{.Entity}}{result},
+		Data:    []*{{.Package}}.{{.Entity}}{result},
 		Message: "{{.Entity}} retrieved successfully",
 	}
 }
 
-// List{{.EntityPlural}} retrieves a list of {{.EntityLower}}s with optional filtering
-func List{{.EntityPlural}}(query *common.Query[{{.EntityLower}}.{{.Entity}}]) *common.APIResponse[*{{.EntityLower}}.{{.Entity}}] {
-	repo := {{.EntityLower}}.GetRepository()
+// List{{.EntityPlural}} retrieves a list of {{.EntityLower}}s with optional filtering.
+// query.Filter is read by {{.Package}}.Parse{{.Entity}}Filter.
+func {{$recv}}List{{.EntityPlural}}(ctx context.Context, query *common.Query[{{.Package}}.{{.Entity}}]) *common.APIResponse[*{{.Package}}.{{.Entity}}] {
+	repo := {{$repo}}
 
-	filter := query.Filter
+	filter, err := {{.Package}}.Parse{{.Entity}}Filter(query.Filter)
+	if err != nil {
+		return &common.APIResponse[*{{.Package}}.{{.Entity}}]{
+			Status:    common.APIStatus.Invalid,
+			Message:   err.Error(),
+			ErrorCode: "INVALID_FILTER",
+		}
+	}
 	offset := query.Offset
 	limit := query.Limit
 	sort := query.Sort
@@ -161,11 +408,11 @@ func List{{.EntityPlural}}(query *common.Query[{{.EntityLower}}.{{.Entity}}]) *c
 		limit = 10 // default limit
 	}
 
-	results, err := repo.List(filter, offset, limit, sort)
+	results, err := repo.List(ctx, filter, offset, limit, sort)
 	if err != nil {
 		// Convert CommonResponse to typed response
 		errorResp := common.FromError(err)
-		return &common.APIResponse[*{{.EntityLower}}.{{.Entity}}]{
+		return &common.APIResponse[*{{.Package}}.{{.Entity}}]{
 			Status:    common.APIStatus.Invalid,
 			Message:   errorResp.GetMessage(),
 			ErrorCode: errorResp.GetErrorCode(),
@@ -173,12 +420,12 @@ func List{{.EntityPlural}}(query *common.Query[{{.EntityLower}}.{{.Entity}}]) *c
 	}
 
 	// Get total count for pagination
-	total, err := repo.Count(filter)
+	total, err := repo.Count(ctx, filter)
 	if err != nil {
 		total = 0
 	}
 
-	return &common.APIResponse[*{{.EntityLower}}.{{.Entity}}]{
+	return &common.APIResponse[*{{.Package}}.{{.Entity}}]{
 		Status:  common.APIStatus.Ok,
 		Data:    results,
 		Message: "{{.EntityPlural}} retrieved successfully",
@@ -186,32 +433,85 @@ func List{{.EntityPlural}}(query *common.Query[{{.EntityLower}}.{{.Entity}}]) *c
 	}
 }
 
+// {{.Entity}}PageResponse is a page of {{.EntityLower}}s with the cursors of the
+// pages around it.
+type {{.Entity}}PageResponse struct {
+	common.APIResponse[*{{.Package}}.{{.Entity}}]
+	Next string ` + "`json:\"next,omitempty\"`" + `
+	Prev string ` + "`json:\"prev,omitempty\"`" + `
+}
+
+// Page{{.EntityPlural}} retrieves a page of {{.EntityLower}}s by cursor. Total is only
+// set when query.Count is; a failing count fails the request.
+func {{$recv}}Page{{.EntityPlural}}(ctx context.Context, query *{{.Package}}.{{.Entity}}PageQuery) *{{.Entity}}PageResponse {
+	repo := {{$repo}}
+
+	limit := query.Limit
+	if limit == 0 {
+		limit = 10 // default limit
+	}
+
+	page, err := repo.ListPage(ctx, query.Filter, query.Cursor, limit)
+	if err != nil {
+		// Convert CommonResponse to typed response
+		errorResp := common.FromError(err)
+		return &{{.Entity}}PageResponse{APIResponse: common.APIResponse[*{{.Package}}.{{.Entity}}]{
+			Status:    common.APIStatus.Invalid,
+			Message:   errorResp.GetMessage(),
+			ErrorCode: errorResp.GetErrorCode(),
+		}}
+	}
+
+	response := &{{.Entity}}PageResponse{
+		APIResponse: common.APIResponse[*{{.Package}}.{{.Entity}}]{
+			Status:  common.APIStatus.Ok,
+			Data:    page.Items,
+			Message: "{{.EntityPlural}} retrieved successfully",
+		},
+		Next: page.Next,
+		Prev: page.Prev,
+	}
+	if query.Count {
+		total, err := repo.Count(ctx, query.Filter)
+		if err != nil {
+			errorResp := common.FromError(err)
+			return &{{.Entity}}PageResponse{APIResponse: common.APIResponse[*{{.Package}}.{{.Entity}}]{
+				Status:    common.APIStatus.Invalid,
+				Message:   errorResp.GetMessage(),
+				ErrorCode: errorResp.GetErrorCode(),
+			}}
+		}
+		response.Total = total
+	}
+	return response
+}
+
 // Update{{.Entity}} updates an existing {{.EntityLower}}
-func Update{{.Entity}}({{.EntityLower}}ID string, data *{{.EntityLower}}.{{.Entity}}) *common.APIResponse[*{{.EntityLower}}.{{.Entity}}] {
-	repo := {{.EntityLower}}.GetRepository()
-	result, err := repo.UpdateBy{{.Entity}}ID({{.EntityLower}}ID, data)
+func {{$recv}}Update{{.Entity}}(ctx context.Context, {{.EntityLower}}ID string, data *{{.Package}}.{{.Entity}}) *common.APIResponse[*{{.Package}}.{{.Entity}}] {
+	repo := {{$repo}}
+	result, err := repo.UpdateBy{{.Entity}}ID(ctx, {{.EntityLower}}ID, data)
 	if err != nil {
 		// Convert CommonResponse to typed response
 		errorResp := common.FromError(err)
-		return &common.APIResponse[*{{.EntityLower}}.{{.Entity}}]{
+		return &common.APIResponse[*{{.Package}}.{{.Entity}}]{
 			Status:    common.APIStatus.Invalid,
 			Message:   errorResp.GetMessage(),
 			ErrorCode: errorResp.GetErrorCode(),
 		}
 	}
 
-	return &common.APIResponse[*{{.EntityLower}}.{{.Entity}}]{
+	return &common.APIResponse[*{{.Package}}.{{.Entity}}]{
 		Status:  common.APIStatus.Ok,
-		Data:    []*{{.EntityLower}}.{{.Entity}}{result},
+		Data:    []*{{.Package}}.{{.Entity}}{result},
 		Message: "{{.Entity}} updated successfully",
 	}
 }
 
 // Delete{{.Entity}} deletes a {{.EntityLower}} by ID (soft delete)
-func Delete{{.Entity}}({{.EntityLower}}ID string) *common.APIResponse[any] {
-	repo := {{.EntityLower}}.GetRepository()
+func {{$recv}}Delete{{.Entity}}(ctx context.Context, {{.EntityLower}}ID string) *common.APIResponse[any] {
+	repo := {{$repo}}
 
-	err := repo.DeleteBy{{.Entity}}ID({{.EntityLower}}ID)
+	err := repo.DeleteBy{{.Entity}}ID(ctx, {{.EntityLower}}ID)
 	if err != nil {
 		// Convert CommonResponse to typed response
 		errorResp := common.FromError(err)
@@ -230,87 +530,102 @@ func Delete{{.Entity}}({{.EntityLower}}ID string) *common.APIResponse[any] {
 `
 
 var API = `package api
+{{- $recv := ""}}
+{{- $service := "action."}}
+{{- if eq .Style "di"}}{{$recv = printf "(h *%sHandler) " .Entity}}{{$service = "h.service."}}{{end}}
 
 import (
-	{{if hasRequiredFields .Fields}}"regexp"
-	"strings"{{end}}
-
-	"gitlab.silvertiger.tech/go-sdk/go-common/common"
+	"{{.CommonImport}}"{{if eq .Framework "sdk"}}
 	"gitlab.silvertiger.tech/go-sdk/go-common/request"
-	"gitlab.silvertiger.tech/go-sdk/go-common/responder"
+	"gitlab.silvertiger.tech/go-sdk/go-common/responder"{{end}}
 	"{{.Module}}/internal/action"
-	"{{.Module}}/{{.PkgPath}}"
-	constants "{{.Module}}/utils"
+	"{{.ModelImport}}"
+	{{.ConstantsPkg}} "{{.ConstantsImport}}"
 )
 
-{{if hasRequiredFields .Fields}}// Email validation regex
-var emailRegex = regexp.MustCompile(` + "`^[a-zA-Z0-9._%+-]+@[a-zA-Z0-9.-]+\\.[a-zA-Z]{2,}$`" + `)
+{{- if eq .Style "di"}}
 
-// isValidEmail validates email format
-func isValidEmail(email string) bool {
-	return emailRegex.MatchString(strings.TrimSpace(email))
-}{{end}}
+// {{.Entity}}Handler serves the {{.EntityLower}} endpoints with a service.
+type {{.Entity}}Handler struct {
+	service *action.{{.Entity}}Service
+}
+
+// New{{.Entity}}Handler returns the {{.EntityLower}} handlers on service.
+func New{{.Entity}}Handler(service *action.{{.Entity}}Service) *{{.Entity}}Handler {
+	return &{{.Entity}}Handler{service: service}
+}
+{{- end}}
 
 // Create{{.Entity}} creates a new {{.EntityLower}}
-func Create{{.Entity}}(req request.APIRequest, res responder.APIResponder) error {
-	var {{.EntityLower}}Data {{.Entity | lower}}.{{.Entity}}
+func {{$recv}}Create{{.Entity}}(req {{.RequestType}}, res {{.ResponderType}}) error {
+	var {{.EntityLower}}Data {{.Package}}.{{.Entity}}
 	if err := req.ParseBody(&{{.EntityLower}}Data); err != nil {
 		return res.Respond(common.NewErrorResponse(common.APIStatus.Invalid, "INVALID_REQUEST_BODY", "Failed to parse request body: "+err.Error()))
 	}
 
 {{generateValidation .Fields .EntityLower}}
 
-	response := action.Create{{.Entity}}(&{{.EntityLower}}Data)
+	response := {{$service}}Create{{.Entity}}({{.RequestContext}}, &{{.EntityLower}}Data)
 	return res.Respond(response)
 }
 
 // Get{{.Entity}}By{{.Entity}}ID retrieves a {{.EntityLower}} by its {{.Entity}}ID
-func Get{{.Entity}}By{{.Entity}}ID(req request.APIRequest, res responder.APIResponder) error {
-	{{.EntityLower}}ID := req.GetParam(constants.Param{{.Entity}}ID)
+func {{$recv}}Get{{.Entity}}By{{.Entity}}ID(req {{.RequestType}}, res {{.ResponderType}}) error {
+	{{.EntityLower}}ID := req.GetParam({{.ConstantsPkg}}.{{.ParamConst}})
 	if {{.EntityLower}}ID == "" {
-		return res.Respond(common.NewErrorResponse(common.APIStatus.Invalid, "VALIDATION_FAILED", "{{.EntityLower}}_id parameter is required"))
+		return res.Respond(common.NewErrorResponse(common.APIStatus.Invalid, "VALIDATION_FAILED", "{{.ParamName}} parameter is required"))
 	}
 
-	response := action.Get{{.Entity}}By{{.Entity}}ID({{.EntityLower}}ID)
+	response := {{$service}}Get{{.Entity}}By{{.Entity}}ID({{.RequestContext}}, {{.EntityLower}}ID)
 	return res.Respond(response)
 }
 
 // Query{{.EntityPlural}} retrieves a list of {{.EntityLower}}s with optional filtering
-func Query{{.EntityPlural}}(req request.APIRequest, res responder.APIResponder) error {
-	var query common.Query[{{.Entity | lower}}.{{.Entity}}]
+func {{$recv}}Query{{.EntityPlural}}(req {{.RequestType}}, res {{.ResponderType}}) error {
+	var query common.Query[{{.Package}}.{{.Entity}}]
 	if err := req.ParseBody(&query); err != nil {
 		return res.Respond(common.FromError(err))
 	}
 
-	return res.Respond(action.List{{.EntityPlural}}(&query))
+	return res.Respond({{$service}}List{{.EntityPlural}}({{.RequestContext}}, &query))
+}
+
+// Page{{.EntityPlural}} retrieves a page of {{.EntityLower}}s by cursor
+func {{$recv}}Page{{.EntityPlural}}(req {{.RequestType}}, res {{.ResponderType}}) error {
+	var query {{.Package}}.{{.Entity}}PageQuery
+	if err := req.ParseBody(&query); err != nil {
+		return res.Respond(common.NewErrorResponse(common.APIStatus.Invalid, "INVALID_REQUEST_BODY", "Failed to parse request body: "+err.Error()))
+	}
+
+	return res.Respond({{$service}}Page{{.EntityPlural}}({{.RequestContext}}, &query))
 }
 
 // Update{{.Entity}} updates an existing {{.EntityLower}}
-func Update{{.Entity}}(req request.APIRequest, res responder.APIResponder) error {
-	{{.EntityLower}}ID := req.GetParam(constants.Param{{.Entity}}ID)
+func {{$recv}}Update{{.Entity}}(req {{.RequestType}}, res {{.ResponderType}}) error {
+	{{.EntityLower}}ID := req.GetParam({{.ConstantsPkg}}.{{.ParamConst}})
 	if {{.EntityLower}}ID == "" {
 		return res.Respond(common.NewErrorResponse(common.APIStatus.Invalid, "VALIDATION_FAILED", "id parameter is required"))
 	}
 
-	var {{.EntityLower}}Data {{.Entity | lower}}.{{.Entity}}
+	var {{.EntityLower}}Data {{.Package}}.{{.Entity}}
 	if err := req.ParseBody(&{{.EntityLower}}Data); err != nil {
 		return res.Respond(common.NewErrorResponse(common.APIStatus.Invalid, "INVALID_REQUEST_BODY", "Failed to parse request body: "+err.Error()))
 	}
 
 {{generateValidation .Fields .EntityLower}}
 
-	response := action.Update{{.Entity}}({{.EntityLower}}ID, &{{.EntityLower}}Data)
+	response := {{$service}}Update{{.Entity}}({{.RequestContext}}, {{.EntityLower}}ID, &{{.EntityLower}}Data)
 	return res.Respond(response)
 }
 
 // Delete{{.Entity}} deletes a {{.EntityLower}} by ID
-func Delete{{.Entity}}(req request.APIRequest, res responder.APIResponder) error {
-	{{.EntityLower}}ID := req.GetParam(constants.Param{{.Entity}}ID)
+func {{$recv}}Delete{{.Entity}}(req {{.RequestType}}, res {{.ResponderType}}) error {
+	{{.EntityLower}}ID := req.GetParam({{.ConstantsPkg}}.{{.ParamConst}})
 	if {{.EntityLower}}ID == "" {
 		return res.Respond(common.NewErrorResponse(common.APIStatus.Invalid, "VALIDATION_FAILED", "id parameter is required"))
 	}
 
-	response := action.Delete{{.Entity}}({{.EntityLower}}ID)
+	response := {{$service}}Delete{{.Entity}}({{.RequestContext}}, {{.EntityLower}}ID)
 	return res.Respond(response)
 }
 `
@@ -318,56 +633,357 @@ func Delete{{.Entity}}(req request.APIRequest, res responder.APIResponder) error
 var Client = `package client
 
 import (
-	"gitlab.silvertiger.tech/go-sdk/go-common/common"
-	"{{.Module}}/{{.PkgPath}}"
+	"context"
+	"fmt"
+	"iter"
+
+	"{{.CommonImport}}"
+	"{{.ModelImport}}"
 )
 
 // Create{{.Entity}} creates a new {{.EntityLower}}
-func (c *BackendServiceClient) Create{{.Entity}}(data *{{.Entity | lower}}.{{.Entity}}) *common.APIResponse[*{{.Entity | lower}}.{{.Entity}}] {
-	response := &common.APIResponse[*{{.Entity | lower}}.{{.Entity}}]{}
-	c.makeRequest("POST", "/v1/{{.EntityLower}}", nil, data, response)
+func (c *BackendServiceClient) Create{{.Entity}}(ctx context.Context, data *{{.Package}}.{{.Entity}}) *common.APIResponse[*{{.Package}}.{{.Entity}}] {
+	response := &common.APIResponse[*{{.Package}}.{{.Entity}}]{}
+	c.makeRequest(ctx, "{{.Routes.Create.Method}}", "{{.Routes.Create.Path}}", nil, data, response)
 
 	return response
 }
 
-// Get{{.Entity}}By{{.Entity}}ID retrieves a {{.EntityLower}} by its {{.EntityLower}}_id
-func (c *BackendServiceClient) Get{{.Entity}}(id string) *common.APIResponse[*{{.Entity | lower}}.{{.Entity}}] {
+// Get{{.Entity}}By{{.Entity}}ID retrieves a {{.EntityLower}} by its {{.ParamName}}
+func (c *BackendServiceClient) Get{{.Entity}}(ctx context.Context, id string) *common.APIResponse[*{{.Package}}.{{.Entity}}] {
 	params := map[string]string{
-		"{{.EntityLower}}_id": id,
+		"{{.Routes.Get.Param}}": id,
 	}
-	response := &common.APIResponse[*{{.Entity | lower}}.{{.Entity}}]{}
-	c.makeRequest("GET", "/v1/{{.EntityLower}}", params, nil, response)
+	response := &common.APIResponse[*{{.Package}}.{{.Entity}}]{}
+	c.makeRequest(ctx, "{{.Routes.Get.Method}}", "{{.Routes.Get.Path}}", params, nil, response)
 
 	return response
 }
 
 // List{{.EntityPlural}} retrieves a list of {{.EntityLower}}s with filtering
-func (c *BackendServiceClient) List{{.EntityPlural}}(query *common.Query[{{.Entity | lower}}.{{.Entity}}]) *common.APIResponse[*{{.Entity | lower}}.{{.Entity}}] {
-	response := &common.APIResponse[*{{.Entity | lower}}.{{.Entity}}]{}
-	c.makeRequest("QUERY", "/v1/{{.EntityLower}}s", nil, query, response)
+func (c *BackendServiceClient) List{{.EntityPlural}}(ctx context.Context, query *common.Query[{{.Package}}.{{.Entity}}]) *common.APIResponse[*{{.Package}}.{{.Entity}}] {
+	response := &common.APIResponse[*{{.Package}}.{{.Entity}}]{}
+	c.makeRequest(ctx, "{{.Routes.List.Method}}", "{{.Routes.List.Path}}", nil, query, response)
 
 	return response
 }
 
+// Find{{.EntityPlural}} retrieves the {{.EntityLower}}s matching filter, built with
+// {{.Package}}.New{{.Entity}}Filter().
+func (c *BackendServiceClient) Find{{.EntityPlural}}(ctx context.Context, filter *{{.Package}}.{{.Entity}}Filter, offset, limit int64, sort map[string]int) *common.APIResponse[*{{.Package}}.{{.Entity}}] {
+	return c.List{{.EntityPlural}}(ctx, &common.Query[{{.Package}}.{{.Entity}}]{Filter: filter, Offset: offset, Limit: limit, Sort: sort})
+}
+
+// {{.Entity}}PageResponse is a page of {{.EntityLower}}s with the cursors of the
+// pages around it.
+type {{.Entity}}PageResponse struct {
+	common.APIResponse[*{{.Package}}.{{.Entity}}]
+	Next string ` + "`json:\"next,omitempty\"`" + `
+	Prev string ` + "`json:\"prev,omitempty\"`" + `
+}
+
+// Page{{.EntityPlural}} retrieves the page of {{.EntityLower}}s query.Cursor points to
+func (c *BackendServiceClient) Page{{.EntityPlural}}(ctx context.Context, query *{{.Package}}.{{.Entity}}PageQuery) *{{.Entity}}PageResponse {
+	response := &{{.Entity}}PageResponse{}
+	c.makeRequest(ctx, "{{.Routes.Page.Method}}", "{{.Routes.Page.Path}}", nil, query, response)
+
+	return response
+}
+
+// All{{.EntityPlural}} iterates over all {{.EntityLower}}s matching filter, fetching
+// pages of pageSize {{.EntityLower}}s as needed. It stops after yielding the
+// first error.
+func (c *BackendServiceClient) All{{.EntityPlural}}(ctx context.Context, filter *{{.Package}}.{{.Entity}}Filter, pageSize int64) iter.Seq2[*{{.Package}}.{{.Entity}}, error] {
+	return func(yield func(*{{.Package}}.{{.Entity}}, error) bool) {
+		query := &{{.Package}}.{{.Entity}}PageQuery{Filter: filter, Limit: pageSize}
+		for {
+			response := &{{.Entity}}PageResponse{}
+			if err := c.makeRequest(ctx, "{{.Routes.Page.Method}}", "{{.Routes.Page.Path}}", nil, query, response); err != nil {
+				yield(nil, err)
+				return
+			}
+			if response.Status != common.APIStatus.Ok {
+				yield(nil, fmt.Errorf("page {{.EntityLower}}s: %s %s", response.ErrorCode, response.Message))
+				return
+			}
+			for _, item := range response.Data {
+				if !yield(item, nil) {
+					return
+				}
+			}
+			if response.Next == "" {
+				return
+			}
+			query.Cursor = response.Next
+		}
+	}
+}
+
 // Update{{.Entity}} updates an existing {{.EntityLower}}
-func (c *BackendServiceClient) Update{{.Entity}}(id string, data *{{.Entity | lower}}.{{.Entity}}) *common.APIResponse[*{{.Entity | lower}}.{{.Entity}}] {
+func (c *BackendServiceClient) Update{{.Entity}}(ctx context.Context, id string, data *{{.Package}}.{{.Entity}}) *common.APIResponse[*{{.Package}}.{{.Entity}}] {
 	params := map[string]string{
-		"{{.EntityLower}}_id": id,
+		"{{.Routes.Update.Param}}": id,
 	}
-	response := &common.APIResponse[*{{.Entity | lower}}.{{.Entity}}]{}
-	c.makeRequest("PUT", "/v1/{{.EntityLower}}", params, data, response)
+	response := &common.APIResponse[*{{.Package}}.{{.Entity}}]{}
+	c.makeRequest(ctx, "{{.Routes.Update.Method}}", "{{.Routes.Update.Path}}", params, data, response)
 
 	return response
 }
 
 // Delete{{.Entity}} deletes a {{.EntityLower}} by ID
-func (c *BackendServiceClient) Delete{{.Entity}}(id string) *common.APIResponse[any] {
+func (c *BackendServiceClient) Delete{{.Entity}}(ctx context.Context, id string) *common.APIResponse[any] {
 	params := map[string]string{
-		"{{.EntityLower}}_id": id,
+		"{{.Routes.Delete.Param}}": id,
 	}
 	response := &common.APIResponse[any]{}
-	c.makeRequest("DELETE", "/v1/{{.EntityLower}}", params, nil, response)
+	c.makeRequest(ctx, "{{.Routes.Delete.Method}}", "{{.Routes.Delete.Path}}", params, nil, response)
 
 	return response
 }
 `
+
+// routeTable is the route table of every routes variant. With the di style
+// it also holds the Handlers of all entities and their wiring.
+const routeTable = `{{if eq .Style "di" -}}
+// Handlers holds the handlers of every entity.
+type Handlers struct {
+{{- range .Entities}}
+	{{.}} *{{.}}Handler
+{{- end}}
+}
+
+// NewHandlers wires the handlers of every entity to a service on its
+// repository in repos.
+func NewHandlers(repos *model.Repositories) *Handlers {
+	return &Handlers{
+{{- range .Entities}}
+		{{.}}: New{{.}}Handler(action.New{{.}}Service(repos.{{.}})),
+{{- end}}
+	}
+}
+
+// Routes lists the endpoints of all entities.
+func (h *Handlers) Routes() []Route {
+	return []Route{
+{{- range .Routes}}
+		{Method: "{{.Method}}", Path: "{{.Path}}", Handler: {{.Handler}}},
+{{- end}}
+	}
+}
+{{- else -}}
+// Routes lists the endpoints of all entities.
+var Routes = []Route{
+{{- range .Routes}}
+	{Method: "{{.Method}}", Path: "{{.Path}}", Handler: {{.Handler}}},
+{{- end}}
+}
+{{- end}}
+`
+
+// diImports are the imports of the wiring in the route table.
+const diImports = `{{- if eq .Style "di"}}
+
+	"{{.ActionImport}}"
+	"{{.ModelImport}}"
+{{- end}}`
+
+var Routes = `package api
+
+import (
+	"fmt"
+
+	"gitlab.silvertiger.tech/go-sdk/go-common/request"
+	"gitlab.silvertiger.tech/go-sdk/go-common/responder"
+` + diImports + `
+)
+
+// Handler is the signature of the generated API handlers.
+type Handler = func(req request.APIRequest, res responder.APIResponder) error
+
+// Route is a generated endpoint.
+type Route struct {
+	Method  string
+	Path    string
+	Handler Handler
+}
+
+` + routeTable + `
+// RegisterRoutes registers every route through register, which adapts the
+// handler registration of your server, e.g.
+//
+//	{{if eq .Style "di"}}handlers{{else}}api{{end}}.RegisterRoutes(func(method, path string, h api.Handler) error {
+//		return server.SetHandler(method, path, h)
+//	})
+func {{if eq .Style "di"}}(h *Handlers) {{end}}RegisterRoutes(register func(method, path string, handler Handler) error) error {
+	for _, r := range {{if eq .Style "di"}}h.Routes(){{else}}Routes{{end}} {
+		if err := register(r.Method, r.Path, r.Handler); err != nil {
+			return fmt.Errorf("register %s %s: %w", r.Method, r.Path, err)
+		}
+	}
+	return nil
+}
+`
+
+// Helpers are the declarations the handlers of every entity use. They are
+// written to their own file, rendered whenever the api layer is, so that the
+// handlers of a partial run compile without the route registration file.
+var Helpers = `package api
+
+import (
+	"context"
+	"regexp"
+	"strings"
+{{- if eq .Framework "sdk"}}
+
+	"gitlab.silvertiger.tech/go-sdk/go-common/request"
+{{- end}}
+)
+{{if eq .Framework "sdk"}}
+// requestContext returns the context the handlers pass down to the action
+// and repository layers. request.APIRequest only parses the body and reads
+// parameters, it carries no context, so this is context.Background(): the
+// action and repository calls are not cancelled with the request and have
+// no deadline.
+func requestContext(req request.APIRequest) context.Context {
+	return context.Background()
+}
+{{else}}` + handlerTypes + `{{end}}
+// emailRegex matches the addresses accepted by the email validation of
+// the generated handlers.
+var emailRegex = regexp.MustCompile(` + "`^[a-zA-Z0-9._%+-]+@[a-zA-Z0-9.-]+\\.[a-zA-Z]{2,}$`" + `)
+
+// isValidEmail validates email format
+func isValidEmail(email string) bool {
+	return emailRegex.MatchString(strings.TrimSpace(email))
+}
+`
+
+var Registry = `package model
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"{{.DBImport}}"
+{{range .Packages}}
+	{{.Alias}} "{{.Import}}"
+{{- end}}
+)
+
+// Entity is an entity registered in the model registry.
+type Entity struct {
+	Name       string
+	Package    string
+	Collection string
+}
+
+// Entities lists every registered entity with its collection, e.g. for
+// health checks and admin tooling.
+var Entities = []Entity{
+{{- range .Entities}}
+	{Name: "{{.Name}}", Package: "{{.Package}}", Collection: "{{.Collection}}"},
+{{- end}}
+}
+
+{{- if eq .Style "di"}}
+
+// Repositories holds the repository of every entity.
+type Repositories struct {
+{{- range .Entities}}
+	{{.Name}} {{.Alias}}.Repository
+{{- end}}
+}
+{{- else}}
+
+// initializers holds the Init function of every model package.
+var initializers = []struct {
+	pkg  string
+	init func(context.Context, {{.DBType}}) error
+}{
+{{- range .Packages}}
+	{"{{.Dir}}", {{.Alias}}.Init},
+{{- end}}
+}
+{{- end}}
+
+// InitOption configures {{if eq .Style "di"}}NewRepositories{{else}}InitAll{{end}}.
+type InitOption func(*initOptions)
+
+type initOptions struct {
+	parallel bool
+}
+
+// Parallel initialises the model packages concurrently.
+func Parallel() InitOption {
+	return func(o *initOptions) { o.parallel = true }
+}
+
+{{- if eq .Style "di"}}
+
+// NewRepositories creates the repository of every entity on database. A
+// failing repository does not stop the others: its field stays nil and
+// all errors are returned joined. ctx is passed to every NewRepository;
+// repositories that have not started when it is done are skipped.
+func NewRepositories(ctx context.Context, database {{.DBType}}, opts ...InitOption) (*Repositories, error) {
+	var o initOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	repos := &Repositories{}
+	initializers := []struct {
+		pkg  string
+		init func(context.Context, {{.DBType}}) error
+	}{
+{{- range .Entities}}
+		{"{{.Package}}", func(ctx context.Context, database {{$.DBType}}) (err error) {
+			repos.{{.Name}}, err = {{.Alias}}.NewRepository(ctx, database)
+			return err
+		}},
+{{- end}}
+	}
+{{- else}}
+
+// InitAll calls the Init function of every model package. A failing
+// package does not stop the others; all errors are returned joined. ctx
+// is passed to every Init; packages that have not started when it is done
+// are skipped.
+func InitAll(ctx context.Context, database {{.DBType}}, opts ...InitOption) error {
+	var o initOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+{{- end}}
+
+	errs := make([]error, len(initializers))
+	run := func(i int) {
+		if err := ctx.Err(); err != nil {
+			errs[i] = fmt.Errorf("init %s: %w", initializers[i].pkg, err)
+			return
+		}
+		if err := initializers[i].init(ctx, database); err != nil {
+			errs[i] = fmt.Errorf("init %s: %w", initializers[i].pkg, err)
+		}
+	}
+
+	if !o.parallel {
+		for i := range initializers {
+			run(i)
+		}
+		return {{if eq .Style "di"}}repos, {{end}}errors.Join(errs...)
+	}
+
+	var wg sync.WaitGroup
+	for i := range initializers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			run(i)
+		}()
+	}
+	wg.Wait()
+	return {{if eq .Style "di"}}repos, {{end}}errors.Join(errs...)
+}
+`
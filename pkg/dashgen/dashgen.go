@@ -0,0 +1,235 @@
+// Package dashgen is the embeddable API of the dashgen code generator.
+//
+// It exposes the same pipeline as the dashgen command: discover the data.go
+// files of a project, parse them into entities and render the generated
+// layers. Rendering happens in memory, so callers can inspect or
+// post-process the files before deciding to write them:
+//
+//	paths, err := dashgen.Discover("path/to/project")
+//	entities, err := dashgen.ParseFiles(paths...)
+//	g := dashgen.New(dashgen.WithModule("github.com/acme/app"), dashgen.WithRoot("path/to/project"))
+//	files, err := g.Render(entities)
+package dashgen
+
+import (
+	"io"
+
+	"github.com/gotech-hub/dashgen/internal/generator"
+	"github.com/gotech-hub/dashgen/internal/parser"
+)
+
+// Entity is a struct annotated with @entity, the intermediate
+// representation every template is rendered from.
+type Entity = parser.Entity
+
+// Field is a field of an entity.
+type Field = parser.Field
+
+// Index is a compound index declared with an @index comment.
+type Index = parser.Index
+
+// IndexField is a single field of an Index.
+type IndexField = parser.IndexField
+
+// Condition is a field=value condition of a partial Index.
+type Condition = parser.Condition
+
+// File is a rendered file. Its Path is relative to the project root.
+type File = generator.File
+
+// Report describes what Generate did with every entity and target.
+type Report = generator.Report
+
+// Action is what happened to a single target file.
+type Action = generator.Action
+
+// Actions reported for target files.
+const (
+	ActionCreated         = generator.ActionCreated
+	ActionOverwritten     = generator.ActionOverwritten
+	ActionSkippedExisting = generator.ActionSkippedExisting
+	ActionStale           = generator.ActionStale
+	ActionUnchanged       = generator.ActionUnchanged
+	ActionError           = generator.ActionError
+)
+
+// Header is the provenance header of a file rendered for an entity.
+type Header = generator.Header
+
+// ParseHeader reads the header of a file rendered by dashgen. It reports
+// false for files dashgen does not own.
+func ParseHeader(content []byte) (Header, bool) {
+	return generator.ParseHeader(content)
+}
+
+// Discover returns the data.go files of the model packages below root.
+func Discover(root string) ([]string, error) {
+	return parser.Discover(root)
+}
+
+// ParseFile returns the entities declared in a single data.go file.
+func ParseFile(path string) ([]Entity, error) {
+	return parser.ParseDataGo(path)
+}
+
+// ParseFiles returns the entities of all given data.go files in order. A
+// file that fails to parse does not stop the others: the entities of the
+// valid files are returned together with the joined *FileError failures.
+func ParseFiles(paths ...string) ([]Entity, error) {
+	return parser.ParseFiles(paths)
+}
+
+// Filter returns the entities whose name matches one of include (all when
+// include is empty) and none of exclude. Patterns are names or globs such
+// as "Order*"; a pattern matching no entity is reported as an error that
+// lists the available entities.
+func Filter(entities []Entity, include, exclude []string) ([]Entity, error) {
+	return parser.Filter(entities, include, exclude)
+}
+
+// FileError is the failure to parse a single data.go file.
+type FileError = parser.FileError
+
+// EntityError is the failure to generate a single entity.
+type EntityError = generator.EntityError
+
+// Generator renders and writes the generated layers of a project.
+type Generator struct {
+	cfg generator.Config
+}
+
+// Option configures a Generator.
+type Option func(*Generator)
+
+// New returns a Generator for the project in the current directory. Logs
+// are discarded unless WithLogger is given.
+func New(opts ...Option) *Generator {
+	g := &Generator{cfg: generator.Config{ProjectRoot: "."}}
+	for _, opt := range opts {
+		opt(g)
+	}
+	return g
+}
+
+// WithModule sets the Go module path of the project root used in generated
+// imports. Without it the module is detected from go.mod or go.work.
+func WithModule(path string) Option {
+	return func(g *Generator) { g.cfg.ModulePath = path }
+}
+
+// WithRoot sets the project root the generated paths are relative to.
+func WithRoot(dir string) Option {
+	return func(g *Generator) { g.cfg.ProjectRoot = dir }
+}
+
+// WithForce makes Generate overwrite existing files.
+func WithForce(force bool) Option {
+	return func(g *Generator) { g.cfg.Force = force }
+}
+
+// WithDryRun makes Generate report what it would do without writing.
+func WithDryRun(dryRun bool) Option {
+	return func(g *Generator) { g.cfg.DryRun = dryRun }
+}
+
+// WithJobs sets how many entities are rendered concurrently.
+func WithJobs(n int) Option {
+	return func(g *Generator) { g.cfg.Jobs = n }
+}
+
+// WithKeepGoing makes Generate write every valid entity and return all
+// failures at the end instead of stopping at the first one.
+func WithKeepGoing(keepGoing bool) Option {
+	return func(g *Generator) { g.cfg.KeepGoing = keepGoing }
+}
+
+// WithFramework sets the HTTP framework of the generated handlers and
+// route registration: "sdk" (the default), "nethttp", "chi", "gin" or
+// "echo".
+func WithFramework(name string) Option {
+	return func(g *Generator) { g.cfg.Framework = name }
+}
+
+// WithBackend sets the storage backend of the model layer: "mongo" (the
+// default), "postgres" or "sqlite".
+func WithBackend(name string) Option {
+	return func(g *Generator) { g.cfg.Backend = name }
+}
+
+// WithStyle sets how the generated layers reach their dependencies:
+// "global" (the default) keeps repositories in package variables, "di"
+// generates constructors and services and handlers that hold their
+// dependencies.
+func WithStyle(name string) Option {
+	return func(g *Generator) { g.cfg.Style = name }
+}
+
+// WithTemplates sets a directory of *.tmpl files rendered instead of the
+// built-in templates of the same name (init, repository, filter, cursor,
+// memory, action, api, helpers, client, routes, registry). They have access
+// to the same template functions.
+func WithTemplates(dir string) Option {
+	return func(g *Generator) { g.cfg.TemplatesDir = dir }
+}
+
+// WithLayers restricts the generated layers: only lists the layers to
+// render (all when empty) and skip the layers to leave out. Layers are
+// "model", "repository", "memory", "action", "api", "client", "routes",
+// "registry", "constants" and the custom layers of WithTemplates.
+func WithLayers(only, skip []string) Option {
+	return func(g *Generator) {
+		g.cfg.Only = only
+		g.cfg.Skip = skip
+	}
+}
+
+// WithVersion sets the generator version written into the header of
+// generated files.
+func WithVersion(version string) Option {
+	return func(g *Generator) { g.cfg.Version = version }
+}
+
+// WithLogger sets where progress messages are written.
+func WithLogger(w io.Writer) Option {
+	return func(g *Generator) { g.cfg.Log = w }
+}
+
+// WithConstants sets the file holding the API parameter constants
+// (relative to the root), the package name used when it is created and the
+// constant naming scheme, in which "{Entity}" is replaced by the entity
+// name. Empty values keep the defaults.
+func WithConstants(file, pkg, naming string) Option {
+	return func(g *Generator) {
+		g.cfg.ConstantsFile = file
+		g.cfg.ConstantsPackage = pkg
+		g.cfg.ConstantName = naming
+	}
+}
+
+// WithPruneConstants removes constants whose entity is not among the
+// rendered entities. Only enable it when rendering the whole project.
+func WithPruneConstants(prune bool) Option {
+	return func(g *Generator) { g.cfg.PruneConstants = prune }
+}
+
+// WithProjectFiles renders the files built from all entities, such as the
+// route registration file internal/api/zz_routes.go. Only enable it when
+// rendering the whole project.
+func WithProjectFiles(enable bool) Option {
+	return func(g *Generator) { g.cfg.ProjectFiles = enable }
+}
+
+// Render renders every file for entities in memory. Nothing is written;
+// the constants file is read so that the returned version merges into it.
+func (g *Generator) Render(entities []Entity) ([]File, error) {
+	return generator.Render(entities, g.cfg)
+}
+
+// Generate renders entities and writes the result below the project root.
+// Files are written atomically: without WithKeepGoing either every file is
+// written or none is; with it the files of the valid entities are written
+// together and the failed entities are left untouched. The report is
+// returned even when generation fails.
+func (g *Generator) Generate(entities []Entity) (*Report, error) {
+	return generator.Generate(entities, g.cfg)
+}
@@ -0,0 +1,140 @@
+package dashgen_test
+
+import (
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/gotech-hub/dashgen/pkg/dashgen"
+)
+
+const orderData = `package order
+
+// @entity db:orders
+// @index status:1 partial:status=open
+type Order struct {
+	ID      string ` + "`json:\"id\" bson:\"_id\"`" + `
+	OrderID string ` + "`json:\"order_id\" bson:\"order_id\"`" + `
+	Status  string ` + "`json:\"status\" bson:\"status\"`" + `
+}
+`
+
+// newProject writes a module with an order entity, and a broken data.go
+// when broken is set, and returns its root.
+func newProject(t *testing.T, broken bool) string {
+	t.Helper()
+	root := t.TempDir()
+	files := map[string]string{
+		"go.mod":              "module example.com/shop\n\ngo 1.24\n",
+		"model/order/data.go": orderData,
+	}
+	if broken {
+		files["model/broken/data.go"] = "package broken\n\n// @entity\ntype Broken struct {\n"
+	}
+	for name, content := range files {
+		path := filepath.Join(root, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return root
+}
+
+func TestParse(t *testing.T) {
+	root := newProject(t, true)
+	paths, err := dashgen.Discover(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(paths) != 2 {
+		t.Fatalf("Discover() = %v, want 2 files", paths)
+	}
+
+	entities, err := dashgen.ParseFiles(paths...)
+	var fe *dashgen.FileError
+	if !errors.As(err, &fe) || filepath.Base(filepath.Dir(fe.Path)) != "broken" {
+		t.Fatalf("ParseFiles() error = %v, want a *FileError for the broken file", err)
+	}
+	if len(entities) != 1 || entities[0].Name != "Order" || entities[0].DBName != "orders" {
+		t.Fatalf("ParseFiles() = %+v, want the Order entity", entities)
+	}
+	want := []dashgen.Index{{
+		Fields:  []dashgen.IndexField{{Name: "status", Direction: 1}},
+		Partial: []dashgen.Condition{{Field: "status", Value: "open"}},
+	}}
+	if got := entities[0].Indexes; !reflect.DeepEqual(got, want) {
+		t.Errorf("Indexes = %+v, want %+v", got, want)
+	}
+
+	if _, err := dashgen.Filter(entities, []string{"Customer"}, nil); err == nil {
+		t.Error("Filter() with an unknown entity succeeded")
+	}
+}
+
+func TestRenderAndGenerate(t *testing.T) {
+	root := newProject(t, false)
+	entities, err := dashgen.ParseFile(filepath.Join(root, "model/order/data.go"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	g := dashgen.New(
+		dashgen.WithRoot(root),
+		dashgen.WithLogger(io.Discard),
+		dashgen.WithVersion("v9.9.9"),
+		dashgen.WithLayers([]string{"model", "repository"}, nil),
+	)
+
+	files, err := g.Render(entities)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) == 0 {
+		t.Fatal("Render() returned no files")
+	}
+	for _, f := range files {
+		h, ok := dashgen.ParseHeader(f.Content)
+		if !ok || h.Version != "v9.9.9" || h.Source != "model/order/data.go" {
+			t.Errorf("%s: header = %+v, %v", f.Path, h, ok)
+		}
+		if _, err := os.Stat(filepath.Join(root, f.Path)); !os.IsNotExist(err) {
+			t.Errorf("Render() wrote %s", f.Path)
+		}
+	}
+
+	report, err := dashgen.New(dashgen.WithRoot(root), dashgen.WithLayers([]string{"model"}, nil), dashgen.WithDryRun(true)).Generate(entities)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Count(dashgen.ActionCreated) == 0 {
+		t.Error("dry run reported nothing to create")
+	}
+	if _, err := os.Stat(filepath.Join(root, "model/order/init.go")); !os.IsNotExist(err) {
+		t.Error("dry run wrote model/order/init.go")
+	}
+
+	if _, err := g.Generate(entities); err != nil {
+		t.Fatal(err)
+	}
+	for _, f := range files {
+		got, err := os.ReadFile(filepath.Join(root, f.Path))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(got) != string(f.Content) {
+			t.Errorf("Generate() wrote %s differently from Render()", f.Path)
+		}
+	}
+	report, err = g.Generate(entities)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := report.Count(dashgen.ActionUnchanged); n != len(files) {
+		t.Errorf("second Generate() left %d of %d files unchanged", n, len(files))
+	}
+}
@@ -3,7 +3,7 @@ package constants
 // API parameter constants
 const (
 	ParamExistingID = "existing_id"
-	ParamUserID     = "user_id"
+	ParamOrderID    = "order_id"
 	ParamProductID  = "product_id"
-	ParamOrderID = "order_id"
+	ParamUserID     = "user_id"
 )